@@ -0,0 +1,433 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/nwaples/rardecode/v2"
+)
+
+var (
+	ErrMemberTooLarge = errors.New("archive member too large to buffer")
+)
+
+const (
+	// archiveSeparator joins the name of an archive to the path of a member
+	// within it, e.g. book.cbz!/page001.jpg.
+	archiveSeparator = "!/"
+
+	// maxArchiveDepth bounds how deeply archives nested within archives are
+	// followed.
+	maxArchiveDepth = 8
+
+	// maxMemberMemory bounds how much of a single archive member is held in
+	// memory. Images only need their headers read, so beyond this the member
+	// is spilled to a temporary file.
+	maxMemberMemory = 1 << 20
+
+	// maxMemberBuffer bounds how much of a single archive member is buffered
+	// at all. Archives nested in a zip must be buffered whole to reach their
+	// central directory, and larger ones are skipped.
+	maxMemberBuffer = 256 << 20
+
+	tarMagicOffset = 257
+)
+
+// sniffArchive identifies an archive format from the leading bytes of a
+// file, or returns an empty string if the format is not recognised.
+// Compressed streams are only identified by their compression, and are
+// checked for a tar archive once decompressed.
+func sniffArchive(header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")), bytes.HasPrefix(header, []byte("PK\x05\x06")):
+		return "zip"
+	case bytes.HasPrefix(header, []byte("Rar!\x1a\x07")):
+		return "rar"
+	case bytes.HasPrefix(header, []byte("\x1f\x8b")):
+		return "gzip"
+	case bytes.HasPrefix(header, []byte("\x28\xb5\x2f\xfd")):
+		return "zstd"
+	case isTar(header):
+		return "tar"
+	default:
+		return ""
+	}
+}
+
+// isTar reports whether header starts with a POSIX or GNU tar header.
+func isTar(header []byte) bool {
+	return len(header) >= tarMagicOffset+5 && string(header[tarMagicOffset:tarMagicOffset+5]) == "ustar"
+}
+
+// A memberReader makes a stream, such as a member of a tar archive, seekable
+// by buffering whatever has been read from it so far. Only as much of the
+// stream as the decoders ask for is read, and anything past maxMemberMemory
+// is buffered in a temporary file, which Close removes.
+type memberReader struct {
+	r        io.Reader
+	buf      []byte
+	spill    *os.File
+	buffered int64
+	pos      int64
+	size     int64
+	err      error
+}
+
+func newMemberReader(r io.Reader, size int64) *memberReader {
+	return &memberReader{r: r, size: size}
+}
+
+// fill reads from the underlying stream until at least n bytes are buffered
+// or the stream is exhausted.
+func (m *memberReader) fill(n int64) {
+	if n > maxMemberBuffer {
+		n = maxMemberBuffer
+	}
+
+	for m.buffered < n && m.err == nil {
+		chunk := make([]byte, min(max(n-m.buffered, 32<<10), maxMemberBuffer-m.buffered, maxMemberMemory))
+
+		read, err := io.ReadFull(m.r, chunk)
+
+		if storeErr := m.store(chunk[:read]); storeErr != nil {
+			m.err = storeErr
+
+			return
+		}
+
+		switch {
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			m.err = io.EOF
+		case err != nil:
+			m.err = err
+		case m.buffered >= maxMemberBuffer:
+			m.err = ErrMemberTooLarge
+		}
+	}
+}
+
+// store appends p to the buffer, moving the buffer to a temporary file once
+// it would outgrow maxMemberMemory.
+func (m *memberReader) store(p []byte) error {
+	if m.spill == nil && int64(len(m.buf)+len(p)) > maxMemberMemory {
+		f, err := os.CreateTemp("", "imagesize-*")
+		if err != nil {
+			return err
+		}
+
+		m.spill = f
+
+		if _, err := f.Write(m.buf); err != nil {
+			return err
+		}
+
+		m.buf = nil
+	}
+
+	if m.spill != nil {
+		if _, err := m.spill.Write(p); err != nil {
+			return err
+		}
+	} else {
+		m.buf = append(m.buf, p...)
+	}
+
+	m.buffered += int64(len(p))
+
+	return nil
+}
+
+// Close removes the temporary file holding the buffer, if there is one.
+func (m *memberReader) Close() error {
+	if m.spill == nil {
+		return nil
+	}
+
+	m.spill.Close()
+
+	return os.Remove(m.spill.Name())
+}
+
+func (m *memberReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, os.ErrInvalid
+	}
+
+	m.fill(off + int64(len(p)))
+
+	if off >= m.buffered {
+		if m.err != nil {
+			return 0, m.err
+		}
+
+		return 0, io.EOF
+	}
+
+	var n int
+
+	if m.spill != nil {
+		var err error
+
+		n, err = m.spill.ReadAt(p[:min(int64(len(p)), m.buffered-off)], off)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return n, err
+		}
+	} else {
+		n = copy(p, m.buf[off:])
+	}
+
+	if n < len(p) {
+		if m.err != nil {
+			return n, m.err
+		}
+
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+func (m *memberReader) Read(p []byte) (int, error) {
+	n, err := m.ReadAt(p, m.pos)
+	m.pos += int64(n)
+
+	if n > 0 {
+		return n, nil
+	}
+
+	return n, err
+}
+
+func (m *memberReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += m.pos
+	case io.SeekEnd:
+		if m.size < 0 {
+			m.fill(maxMemberBuffer)
+			m.size = m.buffered
+		}
+
+		offset += m.size
+	default:
+		return 0, os.ErrInvalid
+	}
+
+	if offset < 0 {
+		return 0, os.ErrInvalid
+	}
+
+	m.pos = offset
+
+	return offset, nil
+}
+
+// archiveImages reads the dimensions of every image within an archive,
+// descending into nested archives. Archives which cannot be read are
+// skipped, keeping any images found before the problem.
+func archiveImages(r io.ReadSeeker, data imageData, depth int) ([]imageData, error) {
+	if depth >= maxArchiveDepth {
+		return nil, nil
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	header, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var images []imageData
+
+	switch sniffArchive(header) {
+	case "zip":
+		images, err = zipImages(r, data, depth)
+	case "rar":
+		images, err = rarImages(r, data, depth)
+	case "tar":
+		images, err = tarImages(r, data, depth)
+	case "gzip":
+		var gz *gzip.Reader
+
+		gz, err = gzip.NewReader(r)
+		if err == nil {
+			images, err = tarImages(gz, data, depth)
+		}
+	case "zstd":
+		var zr *zstd.Decoder
+
+		zr, err = zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err == nil {
+			images, err = tarImages(zr, data, depth)
+
+			zr.Close()
+		}
+	default:
+		return nil, nil
+	}
+
+	// Members too large to buffer are reported by memberImages.
+	if err != nil && verbose && !errors.Is(err, ErrMemberTooLarge) {
+		fmt.Fprintf(os.Stderr, "Skipping remainder of %s: %v.\n", data.name, err)
+	}
+
+	return images, nil
+}
+
+// memberImages reads the dimensions of a single archive member, which may
+// itself be an archive. Members which would need more than maxMemberBuffer
+// bytes buffered are skipped.
+func memberImages(r io.Reader, data imageData, member string, size int64, mtime time.Time, depth int) ([]imageData, error) {
+	m := newMemberReader(r, size)
+	defer m.Close()
+
+	name := data.name + archiveSeparator + path.Clean(member)
+
+	images, err := readImages(m, imageData{
+		name:  name,
+		size:  size,
+		mtime: mtime,
+	}, depth+1)
+
+	if errors.Is(m.err, ErrMemberTooLarge) && verbose {
+		fmt.Fprintf(os.Stderr, "Skipping %s: %v.\n", name, ErrMemberTooLarge)
+	}
+
+	return images, err
+}
+
+func zipImages(r io.ReadSeeker, data imageData, depth int) ([]imageData, error) {
+	at, ok := r.(io.ReaderAt)
+	if !ok {
+		return nil, os.ErrInvalid
+	}
+
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	zr, err := zip.NewReader(at, size)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []imageData
+
+	for _, file := range zr.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			continue
+		}
+
+		found, err := memberImages(rc, data, file.Name, int64(file.UncompressedSize64), file.Modified, depth)
+
+		rc.Close()
+
+		if err != nil {
+			return images, err
+		}
+
+		images = append(images, found...)
+	}
+
+	return images, nil
+}
+
+// tarImages reads the members of a tar archive, which may have been
+// decompressed from a gzip or zstd stream. Compressed streams which do not
+// contain a tar archive are ignored.
+func tarImages(r io.Reader, data imageData, depth int) ([]imageData, error) {
+	br := bufio.NewReader(r)
+
+	if header, _ := br.Peek(sniffLength); !isTar(header) {
+		return nil, nil
+	}
+
+	tr := tar.NewReader(br)
+
+	var images []imageData
+
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return images, nil
+		}
+
+		if err != nil {
+			return images, err
+		}
+
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		found, err := memberImages(tr, data, h.Name, h.Size, h.ModTime, depth)
+		if err != nil {
+			return images, err
+		}
+
+		images = append(images, found...)
+	}
+}
+
+func rarImages(r io.Reader, data imageData, depth int) ([]imageData, error) {
+	rr, err := rardecode.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []imageData
+
+	for {
+		h, err := rr.Next()
+		if errors.Is(err, io.EOF) {
+			return images, nil
+		}
+
+		if err != nil {
+			return images, err
+		}
+
+		if h.IsDir || h.Encrypted {
+			continue
+		}
+
+		size := h.UnPackedSize
+		if h.UnKnownSize {
+			size = -1
+		}
+
+		found, err := memberImages(rr, data, h.Name, size, h.ModificationTime, depth)
+		if err != nil {
+			return images, err
+		}
+
+		images = append(images, found...)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"math/rand/v2"
+	"os"
+	"testing"
+)
+
+func TestMemberReader(t *testing.T) {
+	tests := []struct {
+		name   string
+		length int
+		spills bool
+	}{
+		{"empty", 0, false},
+		{"in memory", maxMemberMemory / 2, false},
+		{"at memory limit", maxMemberMemory, false},
+		{"spilled", maxMemberMemory*3 + 17, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content := make([]byte, test.length)
+			for i := range content {
+				content[i] = byte(rand.IntN(256))
+			}
+
+			m := newMemberReader(bytes.NewReader(content), -1)
+
+			end, err := m.Seek(0, io.SeekEnd)
+			if err != nil || end != int64(test.length) {
+				t.Fatalf("got end %d and error %v, want %d", end, err, test.length)
+			}
+
+			if (m.spill != nil) != test.spills {
+				t.Errorf("got spilled %v, want %v", m.spill != nil, test.spills)
+			}
+
+			for _, off := range []int{0, test.length / 2, max(test.length-5, 0)} {
+				p := make([]byte, 10)
+
+				n, err := m.ReadAt(p, int64(off))
+
+				want := content[off:min(off+10, test.length)]
+				if !bytes.Equal(p[:n], want) {
+					t.Errorf("ReadAt(%d) got %v, want %v", off, p[:n], want)
+				}
+
+				if n < len(p) && err != io.EOF {
+					t.Errorf("ReadAt(%d) got error %v, want EOF", off, err)
+				}
+			}
+
+			if _, err := m.Seek(0, io.SeekStart); err != nil {
+				t.Fatal(err)
+			}
+
+			all, err := io.ReadAll(m)
+			if err != nil || !bytes.Equal(all, content) {
+				t.Errorf("ReadAll got %d bytes and error %v, want %d bytes", len(all), err, test.length)
+			}
+
+			var spill string
+			if m.spill != nil {
+				spill = m.spill.Name()
+			}
+
+			if err := m.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if spill != "" {
+				if _, err := os.Stat(spill); !os.IsNotExist(err) {
+					t.Errorf("temporary file %s not removed", spill)
+				}
+			}
+		})
+	}
+}
+
+func TestMemberReaderTooLarge(t *testing.T) {
+	m := newMemberReader(io.LimitReader(zeroReader{}, maxMemberBuffer+1), -1)
+	defer m.Close()
+
+	m.Seek(0, io.SeekEnd)
+
+	if m.err != ErrMemberTooLarge {
+		t.Errorf("got error %v, want %v", m.err, ErrMemberTooLarge)
+	}
+
+	if m.buffered != maxMemberBuffer {
+		t.Errorf("buffered %d bytes, want %d", m.buffered, maxMemberBuffer)
+	}
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+
+	return len(p), nil
+}
@@ -0,0 +1,37 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidDepth = errors.New("depth must not be negative")
+)
+
+// entryDepth returns the depth of an entry from its path relative to the
+// root being scanned. As with find, the root is at depth 0 and its entries
+// are at depth 1.
+func entryDepth(rel string) int {
+	if rel == "." {
+		return 0
+	}
+
+	return strings.Count(rel, "/") + 1
+}
+
+// descendsTo reports whether directories at depth should be descended into,
+// which is only the case if their entries are within --max-depth.
+func descendsTo(depth int) bool {
+	return maxDepth < 0 || depth < maxDepth
+}
+
+// withinDepth reports whether an entry at depth is within --min-depth and
+// --max-depth.
+func withinDepth(depth int) bool {
+	return depth >= minDepth && (maxDepth < 0 || depth <= maxDepth)
+}
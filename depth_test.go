@@ -0,0 +1,140 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestEntryDepth(t *testing.T) {
+	tests := []struct {
+		rel  string
+		want int
+	}{
+		{".", 0},
+		{"a.png", 1},
+		{"a/b.png", 2},
+		{"a/b/c.png", 3},
+	}
+
+	for _, test := range tests {
+		if got := entryDepth(test.rel); got != test.want {
+			t.Errorf("entryDepth(%q) = %d, want %d", test.rel, got, test.want)
+		}
+	}
+}
+
+// testScan scans root with every image matching, returning the paths found
+// relative to root.
+func testScan(t *testing.T, root string) []string {
+	t.Helper()
+
+	results := make(chan imageData)
+	done := make(chan []string)
+
+	go func() {
+		var found []string
+
+		for result := range results {
+			found = append(found, relativePath(root, result.name))
+		}
+
+		done <- found
+	}()
+
+	err := scanPath(root, newComparison(widthField, greater, 0), make(chan int, concurrency), results)
+
+	close(results)
+
+	found := <-done
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slices.Sort(found)
+
+	return found
+}
+
+func TestScanDepth(t *testing.T) {
+	withFlagDefaults(t)
+
+	root := t.TempDir()
+
+	for _, path := range []string{"a.png", "d1/b.png", "d1/d2/c.png"} {
+		full := filepath.Join(root, path)
+
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(full, testPNG(t, 10, 10), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		recursive bool
+		min, max  int
+		want      []string
+	}{
+		{"not recursive", false, 0, -1, []string{"a.png"}},
+		{"recursive", true, 0, -1, []string{"a.png", "d1/b.png", "d1/d2/c.png"}},
+		{"max depth 1", true, 0, 1, []string{"a.png"}},
+		{"max depth 2", true, 0, 2, []string{"a.png", "d1/b.png"}},
+		{"min depth 2", true, 2, -1, []string{"d1/b.png", "d1/d2/c.png"}},
+		{"min and max depth 2", true, 2, 2, []string{"d1/b.png"}},
+		{"max depth 0", true, 0, 0, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			savedRecursive, savedMinDepth, savedMaxDepth := recursive, minDepth, maxDepth
+
+			t.Cleanup(func() {
+				recursive, minDepth, maxDepth = savedRecursive, savedMinDepth, savedMaxDepth
+			})
+
+			recursive, minDepth, maxDepth = test.recursive, test.min, test.max
+
+			if got := testScan(t, root); !slices.Equal(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestScanDepthFileArgument(t *testing.T) {
+	withFlagDefaults(t)
+
+	file := filepath.Join(t.TempDir(), "a.png")
+
+	if err := os.WriteFile(file, testPNG(t, 10, 10), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	savedMinDepth := minDepth
+	t.Cleanup(func() { minDepth = savedMinDepth })
+
+	// Files passed directly are at depth 0, as with find.
+	for _, test := range []struct {
+		min   int
+		found int
+	}{
+		{0, 1},
+		{1, 0},
+	} {
+		minDepth = test.min
+
+		if found := testScan(t, file); len(found) != test.found {
+			t.Errorf("min depth %d: got %q", test.min, found)
+		}
+	}
+}
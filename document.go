@@ -0,0 +1,119 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// packageMarkers lists the members which identify a zip file as an office
+// document or e-book package rather than a plain archive.
+var packageMarkers = []string{
+	"[Content_Types].xml",
+	"META-INF/container.xml",
+	"META-INF/manifest.xml",
+}
+
+// packageMimetypes lists the prefixes of the mimetype member which EPUB and
+// OpenDocument packages store first.
+var packageMimetypes = []string{
+	"application/epub+zip",
+	"application/vnd.oasis.opendocument.",
+}
+
+// isPackage reports whether zr is an EPUB, OOXML (DOCX, XLSX, PPTX) or
+// OpenDocument package.
+func isPackage(zr *zip.Reader) bool {
+	for _, file := range zr.File {
+		for _, marker := range packageMarkers {
+			if file.Name == marker {
+				return true
+			}
+		}
+
+		if file.Name != "mimetype" {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			continue
+		}
+
+		mimetype, _ := io.ReadAll(io.LimitReader(rc, 256))
+
+		rc.Close()
+
+		for _, prefix := range packageMimetypes {
+			if strings.HasPrefix(string(mimetype), prefix) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// documentKind identifies r as a PDF file or a document package, returning
+// an empty string if it is neither.
+func documentKind(r io.ReadSeeker) string {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return ""
+	}
+
+	header, err := readHeader(r)
+	if err != nil {
+		return ""
+	}
+
+	switch {
+	case isPDF(header):
+		return "pdf"
+	case sniffArchive(header) != "zip":
+		return ""
+	}
+
+	at, ok := r.(io.ReaderAt)
+	if !ok {
+		return ""
+	}
+
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return ""
+	}
+
+	zr, err := zip.NewReader(at, size)
+	if err != nil || !isPackage(zr) {
+		return ""
+	}
+
+	return "package"
+}
+
+// documentImages reads the dimensions of the images embedded in a PDF file
+// or document package. Packages are zip files, so their images are named
+// after their paths within it, e.g. report.docx!/word/media/image1.png.
+func documentImages(r io.ReadSeeker, data imageData, kind string, depth int) ([]imageData, error) {
+	var images []imageData
+	var err error
+
+	switch kind {
+	case "pdf":
+		images, err = pdfImages(r, data)
+	case "package":
+		images, err = zipImages(r, data, depth)
+	}
+
+	if err != nil && verbose {
+		fmt.Fprintf(os.Stderr, "Skipping remainder of %s: %v.\n", data.name, err)
+	}
+
+	return images, nil
+}
@@ -0,0 +1,176 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	heic "github.com/gen2brain/heic"
+)
+
+var (
+	ErrNoExif      = errors.New("no exif data found")
+	ErrInvalidExif = errors.New("invalid exif data")
+)
+
+const (
+	exifOrientationTag = 0x0112
+	exifShortType      = 3
+)
+
+// orientation returns the EXIF orientation (1-8) of an image, or 1 if the
+// format carries no EXIF data or none could be read.
+func orientation(r io.ReadSeeker, format string) int {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return 1
+	}
+
+	var value int
+	var err error
+
+	switch format {
+	case "jpeg":
+		value, err = jpegOrientation(r)
+	case "heic":
+		var exif *heic.Exif
+
+		exif, err = heic.DecodeExif(r)
+		if err == nil {
+			value = exif.Orientation
+		}
+	default:
+		return 1
+	}
+
+	if err != nil || value < 1 || value > 8 {
+		return 1
+	}
+
+	return value
+}
+
+// swapsAxes reports whether an EXIF orientation rotates the image by 90 or
+// 270 degrees, so that its displayed width and height are transposed.
+func swapsAxes(orientation int) bool {
+	return orientation >= 5 && orientation <= 8
+}
+
+// jpegOrientation scans the JPEG marker segments preceding the image data for
+// an APP1 Exif segment, and reads the orientation from it.
+func jpegOrientation(r io.Reader) (int, error) {
+	br := bufio.NewReader(r)
+
+	var marker [2]byte
+
+	if _, err := io.ReadFull(br, marker[:]); err != nil {
+		return 0, err
+	}
+
+	if marker[0] != 0xff || marker[1] != 0xd8 {
+		return 0, ErrInvalidExif
+	}
+
+	for {
+		if _, err := io.ReadFull(br, marker[:]); err != nil {
+			return 0, err
+		}
+
+		if marker[0] != 0xff {
+			return 0, ErrInvalidExif
+		}
+
+		switch {
+		case marker[1] == 0xff:
+			// Fill bytes may precede a marker.
+			if err := br.UnreadByte(); err != nil {
+				return 0, err
+			}
+
+			continue
+		case marker[1] == 0xd9 || marker[1] == 0xda:
+			return 0, ErrNoExif
+		case marker[1] >= 0xd0 && marker[1] <= 0xd7 || marker[1] == 0x01:
+			continue
+		}
+
+		var length [2]byte
+
+		if _, err := io.ReadFull(br, length[:]); err != nil {
+			return 0, err
+		}
+
+		size := int(binary.BigEndian.Uint16(length[:])) - 2
+		if size < 0 {
+			return 0, ErrInvalidExif
+		}
+
+		if marker[1] != 0xe1 {
+			if _, err := br.Discard(size); err != nil {
+				return 0, err
+			}
+
+			continue
+		}
+
+		segment := make([]byte, size)
+
+		if _, err := io.ReadFull(br, segment); err != nil {
+			return 0, err
+		}
+
+		if !bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			continue
+		}
+
+		return tiffOrientation(segment[6:])
+	}
+}
+
+// tiffOrientation reads the orientation tag from the first IFD of a TIFF
+// structure, as embedded in EXIF segments.
+func tiffOrientation(data []byte) (int, error) {
+	if len(data) < 8 {
+		return 0, ErrInvalidExif
+	}
+
+	var order binary.ByteOrder
+
+	switch string(data[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0, ErrInvalidExif
+	}
+
+	if order.Uint16(data[2:]) != 42 {
+		return 0, ErrInvalidExif
+	}
+
+	offset := int(order.Uint32(data[4:]))
+	if offset < 8 || offset+2 > len(data) {
+		return 0, ErrInvalidExif
+	}
+
+	entries := int(order.Uint16(data[offset:]))
+
+	for i := range entries {
+		entry := offset + 2 + i*12
+		if entry+12 > len(data) {
+			break
+		}
+
+		if order.Uint16(data[entry:]) == exifOrientationTag && order.Uint16(data[entry+2:]) == exifShortType {
+			return int(order.Uint16(data[entry+8:])), nil
+		}
+	}
+
+	return 0, ErrNoExif
+}
@@ -0,0 +1,706 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	ErrInvalidExpression = errors.New("invalid expression")
+	ErrUnknownField      = errors.New("unknown field")
+	ErrTypeMismatch      = errors.New("mismatched types in comparison")
+)
+
+type field int
+
+const (
+	widthField field = iota
+	heightField
+	areaField
+	ratioField
+	formatField
+	sizeField
+	mtimeField
+)
+
+var fieldNames = map[string]field{
+	"width":  widthField,
+	"height": heightField,
+	"area":   areaField,
+	"ratio":  ratioField,
+	"format": formatField,
+	"size":   sizeField,
+	"mtime":  mtimeField,
+}
+
+type valueKind int
+
+const (
+	numberValue valueKind = iota
+	stringValue
+	timeValue
+)
+
+func (k valueKind) String() string {
+	switch k {
+	case stringValue:
+		return "string"
+	case timeValue:
+		return "time"
+	default:
+		return "number"
+	}
+}
+
+type value struct {
+	kind   valueKind
+	number float64
+	text   string
+	time   time.Time
+}
+
+func (f field) kind() valueKind {
+	switch f {
+	case formatField:
+		return stringValue
+	case mtimeField:
+		return timeValue
+	default:
+		return numberValue
+	}
+}
+
+func (f field) value(image *imageData) value {
+	switch f {
+	case widthField:
+		return value{kind: numberValue, number: float64(image.width)}
+	case heightField:
+		return value{kind: numberValue, number: float64(image.height)}
+	case areaField:
+		return value{kind: numberValue, number: float64(pixelCount(*image))}
+	case ratioField:
+		return value{kind: numberValue, number: aspectRatio(*image)}
+	case formatField:
+		return value{kind: stringValue, text: image.format}
+	case sizeField:
+		return value{kind: numberValue, number: float64(image.size)}
+	case mtimeField:
+		return value{kind: timeValue, time: image.mtime}
+	default:
+		return value{}
+	}
+}
+
+type operand interface {
+	kind() valueKind
+	value(image *imageData) value
+}
+
+type literal struct {
+	v value
+}
+
+func numberLiteral(number float64) literal {
+	return literal{v: value{kind: numberValue, number: number}}
+}
+
+func stringLiteral(text string) literal {
+	return literal{v: value{kind: stringValue, text: text}}
+}
+
+func timeLiteral(t time.Time) literal {
+	return literal{v: value{kind: timeValue, time: t}}
+}
+
+func (l literal) kind() valueKind {
+	return l.v.kind
+}
+
+func (l literal) value(_ *imageData) value {
+	return l.v
+}
+
+type compareType int
+
+const (
+	equal compareType = iota
+	notEqual
+	greater
+	greaterOrEqual
+	less
+	lessOrEqual
+)
+
+var compareSymbols = map[string]compareType{
+	"==": equal,
+	"=":  equal,
+	"!=": notEqual,
+	">":  greater,
+	">=": greaterOrEqual,
+	"<":  less,
+	"<=": lessOrEqual,
+}
+
+// orEqual returns the inclusive form of a strict inequality.
+func (c compareType) orEqual() compareType {
+	switch c {
+	case greater:
+		return greaterOrEqual
+	case less:
+		return lessOrEqual
+	default:
+		return c
+	}
+}
+
+func (c compareType) holds(result int) bool {
+	switch c {
+	case equal:
+		return result == 0
+	case notEqual:
+		return result != 0
+	case greater:
+		return result > 0
+	case greaterOrEqual:
+		return result >= 0
+	case less:
+		return result < 0
+	case lessOrEqual:
+		return result <= 0
+	default:
+		return false
+	}
+}
+
+type expression interface {
+	matches(image *imageData) bool
+}
+
+type andExpression struct {
+	left, right expression
+}
+
+func (e *andExpression) matches(image *imageData) bool {
+	return e.left.matches(image) && e.right.matches(image)
+}
+
+type orExpression struct {
+	left, right expression
+}
+
+func (e *orExpression) matches(image *imageData) bool {
+	return e.left.matches(image) || e.right.matches(image)
+}
+
+type notExpression struct {
+	operand expression
+}
+
+func (e *notExpression) matches(image *imageData) bool {
+	return !e.operand.matches(image)
+}
+
+// nonPowerOfTwoExpression matches images with a side whose length is not a
+// power of two, as most texture pipelines require.
+type nonPowerOfTwoExpression struct{}
+
+func (e *nonPowerOfTwoExpression) matches(image *imageData) bool {
+	return !isPowerOfTwo(image.width) || !isPowerOfTwo(image.height)
+}
+
+type comparison struct {
+	left      operand
+	operator  compareType
+	right     operand
+	tolerance float64
+}
+
+func (c *comparison) matches(image *imageData) bool {
+	left, right := c.left.value(image), c.right.value(image)
+
+	var result int
+
+	switch left.kind {
+	case numberValue:
+		result = compareNumbers(left.number, right.number, c.tolerance)
+	case stringValue:
+		result = strings.Compare(left.text, right.text)
+	case timeValue:
+		result = left.time.Compare(right.time)
+	}
+
+	return c.operator.holds(result)
+}
+
+// compareNumbers treats values within tolerance of one another as equal.
+func compareNumbers(a, b, tolerance float64) int {
+	switch {
+	case math.Abs(a-b) <= tolerance:
+		return 0
+	case a < b:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// newComparison builds a comparison between a field and a numeric constant,
+// as used by the width and height subcommands.
+func newComparison(f field, operator compareType, threshold float64) expression {
+	return &comparison{
+		left:     f,
+		operator: operator,
+		right:    numberLiteral(threshold),
+	}
+}
+
+// combine joins two optional expressions with a logical AND.
+func combine(left, right expression) expression {
+	switch {
+	case left == nil:
+		return right
+	case right == nil:
+		return left
+	default:
+		return &andExpression{left: left, right: right}
+	}
+}
+
+// units maps the suffixes accepted on numbers to their multipliers, covering
+// both file sizes and pixel counts.
+var units = map[string]float64{
+	"b":   1,
+	"kb":  1e3,
+	"kib": 1 << 10,
+	"mb":  1e6,
+	"mib": 1 << 20,
+	"gb":  1e9,
+	"gib": 1 << 30,
+	"tb":  1e12,
+	"tib": 1 << 40,
+	"kp":  1e3,
+	"mp":  1e6,
+	"gp":  1e9,
+}
+
+var pixelUnits = map[string]bool{
+	"kp": true,
+	"mp": true,
+	"gp": true,
+}
+
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseRatio accepts ratios such as 16:9 or 4/3, as well as plain numbers.
+func parseRatio(text string) (float64, error) {
+	if strings.IndexFunc(text, unicode.IsLetter) != -1 {
+		return 0, fmt.Errorf("%w: invalid ratio %q", ErrInvalidExpression, text)
+	}
+
+	return parseNumber(text)
+}
+
+// parsePixels accepts plain pixel counts as well as suffixed values such as
+// 12MP or 500KP.
+func parsePixels(text string) (float64, error) {
+	end := strings.IndexFunc(text, unicode.IsLetter)
+	if end != -1 && !pixelUnits[strings.ToLower(text[end:])] || strings.ContainsAny(text, ":/") {
+		return 0, fmt.Errorf("%w: invalid pixel count %q", ErrInvalidExpression, text)
+	}
+
+	return parseNumber(text)
+}
+
+// parseNumber accepts plain numbers, ratios such as 16:9 or 4/3, and values
+// with a unit suffix such as 10MB, 512KiB or 12MP.
+func parseNumber(text string) (float64, error) {
+	if i := strings.IndexAny(text, ":/"); i != -1 {
+		numerator, err := strconv.ParseFloat(text[:i], 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: invalid ratio %q", ErrInvalidExpression, text)
+		}
+
+		denominator, err := strconv.ParseFloat(text[i+1:], 64)
+		if err != nil || denominator == 0 {
+			return 0, fmt.Errorf("%w: invalid ratio %q", ErrInvalidExpression, text)
+		}
+
+		return numerator / denominator, nil
+	}
+
+	end := strings.IndexFunc(text, unicode.IsLetter)
+	if end == -1 {
+		end = len(text)
+	}
+
+	number, err := strconv.ParseFloat(text[:end], 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid number %q", ErrInvalidExpression, text)
+	}
+
+	if end == len(text) {
+		return number, nil
+	}
+
+	multiplier, ok := units[strings.ToLower(text[end:])]
+	if !ok {
+		return 0, fmt.Errorf("%w: unknown unit %q", ErrInvalidExpression, text[end:])
+	}
+
+	return number * multiplier, nil
+}
+
+func parseTime(text string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		t, err := time.ParseInLocation(layout, text, time.Local)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: invalid time %q", ErrInvalidExpression, text)
+}
+
+type tokenType int
+
+const (
+	endToken tokenType = iota
+	identifierToken
+	numberToken
+	stringToken
+	compareToken
+	andToken
+	orToken
+	notToken
+	openToken
+	closeToken
+)
+
+type token struct {
+	kind     tokenType
+	text     string
+	position int
+}
+
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(input); {
+		c := input[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: openToken, text: "(", position: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: closeToken, text: ")", position: i})
+			i++
+		case strings.HasPrefix(input[i:], "&&"):
+			tokens = append(tokens, token{kind: andToken, text: "&&", position: i})
+			i += 2
+		case strings.HasPrefix(input[i:], "||"):
+			tokens = append(tokens, token{kind: orToken, text: "||", position: i})
+			i += 2
+		case strings.HasPrefix(input[i:], "=="),
+			strings.HasPrefix(input[i:], "!="),
+			strings.HasPrefix(input[i:], ">="),
+			strings.HasPrefix(input[i:], "<="):
+			tokens = append(tokens, token{kind: compareToken, text: input[i : i+2], position: i})
+			i += 2
+		case c == '=' || c == '>' || c == '<':
+			tokens = append(tokens, token{kind: compareToken, text: input[i : i+1], position: i})
+			i++
+		case c == '!':
+			tokens = append(tokens, token{kind: notToken, text: "!", position: i})
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(input[i+1:], c)
+			if end == -1 {
+				return nil, fmt.Errorf("%w: unterminated string at position %d", ErrInvalidExpression, i)
+			}
+
+			tokens = append(tokens, token{kind: stringToken, text: input[i+1 : i+1+end], position: i})
+			i += end + 2
+		case c >= '0' && c <= '9' || c == '.':
+			start := i
+
+			for i < len(input) && (isDigit(input[i]) || isLetter(input[i]) || strings.IndexByte(".:/", input[i]) != -1) {
+				i++
+			}
+
+			tokens = append(tokens, token{kind: numberToken, text: input[start:i], position: start})
+		case isLetter(c):
+			start := i
+
+			for i < len(input) && (isLetter(input[i]) || isDigit(input[i])) {
+				i++
+			}
+
+			word := input[start:i]
+
+			switch strings.ToLower(word) {
+			case "and":
+				tokens = append(tokens, token{kind: andToken, text: word, position: start})
+			case "or":
+				tokens = append(tokens, token{kind: orToken, text: word, position: start})
+			case "not":
+				tokens = append(tokens, token{kind: notToken, text: word, position: start})
+			default:
+				tokens = append(tokens, token{kind: identifierToken, text: word, position: start})
+			}
+		default:
+			return nil, fmt.Errorf("%w: unexpected character %q at position %d", ErrInvalidExpression, c, i)
+		}
+	}
+
+	return append(tokens, token{kind: endToken, position: len(input)}), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+
+	if t.kind != endToken {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *parser) unexpected(t token) error {
+	if t.kind == endToken {
+		return fmt.Errorf("%w: unexpected end of expression", ErrInvalidExpression)
+	}
+
+	return fmt.Errorf("%w: unexpected %q at position %d", ErrInvalidExpression, t.text, t.position)
+}
+
+// parseExpression parses a filter such as
+// `width > 1920 && height < 1200 || ratio == 1`.
+//
+// The grammar, from lowest to highest precedence, is:
+//
+//	or         = and { ("||" | "or") and }
+//	and        = unary { ("&&" | "and") unary }
+//	unary      = ("!" | "not") unary | primary
+//	primary    = "(" or ")" | comparison
+//	comparison = operand operator operand
+func parseExpression(input string) (expression, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != endToken {
+		return nil, p.unexpected(t)
+	}
+
+	return e, nil
+}
+
+func (p *parser) parseOr() (expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == orToken {
+		p.next()
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &orExpression{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (expression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == andToken {
+		p.next()
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &andExpression{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseUnary() (expression, error) {
+	if p.peek().kind == notToken {
+		p.next()
+
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return &notExpression{operand: e}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (expression, error) {
+	if p.peek().kind == openToken {
+		p.next()
+
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if t := p.next(); t.kind != closeToken {
+			return nil, p.unexpected(t)
+		}
+
+		return e, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (expression, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.next()
+	if t.kind != compareToken {
+		return nil, p.unexpected(t)
+	}
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	left, right, err = coerce(left, right)
+	if err != nil {
+		return nil, err
+	}
+
+	return &comparison{left: left, operator: compareSymbols[t.text], right: right}, nil
+}
+
+func (p *parser) parseOperand() (operand, error) {
+	t := p.next()
+
+	switch t.kind {
+	case identifierToken:
+		f, ok := fieldNames[strings.ToLower(t.text)]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownField, t.text)
+		}
+
+		return f, nil
+	case numberToken:
+		number, err := parseNumber(t.text)
+		if err != nil {
+			return nil, err
+		}
+
+		return numberLiteral(number), nil
+	case stringToken:
+		return stringLiteral(t.text), nil
+	default:
+		return nil, p.unexpected(t)
+	}
+}
+
+// coerce converts literals to the type of the field they are compared
+// against, so that e.g. `mtime > "2024-01-01"` and `format == "PNG"` work.
+func coerce(left, right operand) (operand, operand, error) {
+	if l, ok := left.(literal); ok {
+		coerced, err := coerceLiteral(l, right.kind())
+
+		return coerced, right, err
+	}
+
+	if r, ok := right.(literal); ok {
+		coerced, err := coerceLiteral(r, left.kind())
+
+		return left, coerced, err
+	}
+
+	if left.kind() != right.kind() {
+		return nil, nil, fmt.Errorf("%w: %s and %s", ErrTypeMismatch, left.kind(), right.kind())
+	}
+
+	return left, right, nil
+}
+
+func coerceLiteral(l literal, kind valueKind) (operand, error) {
+	switch {
+	case l.kind() == kind && kind == stringValue:
+		return stringLiteral(normalizeFormat(l.v.text)), nil
+	case l.kind() == kind:
+		return l, nil
+	case l.kind() == stringValue && kind == timeValue:
+		t, err := parseTime(l.v.text)
+		if err != nil {
+			return nil, err
+		}
+
+		return timeLiteral(t), nil
+	case l.kind() == numberValue && kind == timeValue:
+		seconds, fraction := math.Modf(l.v.number)
+
+		return timeLiteral(time.Unix(int64(seconds), int64(fraction*1e9))), nil
+	default:
+		return nil, fmt.Errorf("%w: %s and %s", ErrTypeMismatch, l.kind(), kind)
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseExpression(t *testing.T) {
+	photo := imageData{
+		width:  4000,
+		height: 3000,
+		format: "jpeg",
+		size:   600_000,
+		mtime:  time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local),
+	}
+
+	video := imageData{
+		width:  1920,
+		height: 1080,
+		format: "png",
+		size:   2_000_000,
+		mtime:  time.Date(2023, 1, 1, 0, 0, 0, 0, time.Local),
+	}
+
+	tests := []struct {
+		input string
+		image imageData
+		want  bool
+	}{
+		// && binds more tightly than ||.
+		{`width > 3000 || height > 5000 && format == "gif"`, photo, true},
+		{`(width > 3000 || height > 5000) && format == "gif"`, photo, false},
+		{`format == "gif" && height > 5000 || width > 3000`, photo, true},
+		{`width > 3000 or height > 5000 and format == "gif"`, photo, true},
+		{`width > 1 && height > 1 && format == "gif"`, photo, false},
+		{`width < 1 || height < 1 || format == "jpeg"`, photo, true},
+
+		// Negation applies to the nearest comparison or group.
+		{`!width > 3000`, photo, false},
+		{`not width > 3000`, photo, false},
+		{`!width > 3000 || format == "jpeg"`, photo, true},
+		{`!(width > 3000 || format == "jpeg")`, photo, false},
+		{`!!width > 3000`, photo, true},
+		{`not not width > 3000`, photo, true},
+
+		// Units.
+		{`area >= 12MP`, photo, true},
+		{`area > 12mp`, photo, false},
+		{`area > 12000KP`, photo, false},
+		{`area < 0.013GP`, photo, true},
+		{`size > 512KiB`, photo, true},
+		{`size > 600KB`, photo, false},
+		{`size < 1MiB`, photo, true},
+		{`size >= 2MB`, video, true},
+		{`size > 1.9MiB`, video, true},
+
+		// Ratios.
+		{`ratio == 16:9`, video, true},
+		{`ratio == 4/3`, photo, true},
+		{`ratio > 16:9`, photo, false},
+		{`ratio >= 1.33`, photo, true},
+		{`ratio != 16:9`, photo, true},
+
+		// Strings and times are coerced to the type of the field.
+		{`format == "JPEG"`, photo, true},
+		{`format == "jpg"`, photo, true},
+		{`format != "png"`, video, false},
+		{`"png" == format`, video, true},
+		{`mtime > "2024-01-01"`, photo, true},
+		{`mtime > "2024-01-01"`, video, false},
+		{`mtime < "2024-06-01 12:30"`, photo, true},
+		{`mtime <= "2023-01-01T00:00:00"`, video, true},
+
+		// Fields compare against one another.
+		{`width > height`, photo, true},
+		{`WIDTH == Height`, photo, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			e, err := parseExpression(test.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := e.matches(&test.image); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  error
+	}{
+		{`format > 5`, ErrTypeMismatch},
+		{`width > "abc"`, ErrTypeMismatch},
+		{`format == width`, ErrTypeMismatch},
+		{`mtime == format`, ErrTypeMismatch},
+		{`mtime > "bad"`, ErrInvalidExpression},
+		{`depth > 1`, ErrUnknownField},
+		{`size > 5XB`, ErrInvalidExpression},
+		{`ratio == 16:0`, ErrInvalidExpression},
+		{`ratio == 16:`, ErrInvalidExpression},
+		{`width >`, ErrInvalidExpression},
+		{`width 5`, ErrInvalidExpression},
+		{`(width > 1`, ErrInvalidExpression},
+		{`width > 1)`, ErrInvalidExpression},
+		{`width > 1 &&`, ErrInvalidExpression},
+		{`width > 1 height > 1`, ErrInvalidExpression},
+		{`!`, ErrInvalidExpression},
+		{``, ErrInvalidExpression},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			_, err := parseExpression(test.input)
+			if !errors.Is(err, test.want) {
+				t.Errorf("got error %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestParseNumbers(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func(string) (float64, error)
+		input string
+		want  float64
+		err   bool
+	}{
+		{"pixels", parsePixels, "12MP", 12e6, false},
+		{"pixels", parsePixels, "500kp", 5e5, false},
+		{"pixels", parsePixels, "1024", 1024, false},
+		{"pixels", parsePixels, "10MB", 0, true},
+		{"pixels", parsePixels, "16:9", 0, true},
+		{"ratio", parseRatio, "16:9", 16.0 / 9, false},
+		{"ratio", parseRatio, "4/3", 4.0 / 3, false},
+		{"ratio", parseRatio, "1.5", 1.5, false},
+		{"ratio", parseRatio, "5MP", 0, true},
+		{"ratio", parseRatio, "1:0", 0, true},
+		{"number", parseNumber, "512KiB", 512 << 10, false},
+		{"number", parseNumber, "10MB", 10e6, false},
+		{"number", parseNumber, "1.5GiB", 1.5 * (1 << 30), false},
+		{"number", parseNumber, "5XB", 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name+" "+test.input, func(t *testing.T) {
+			got, err := test.parse(test.input)
+			if (err != nil) != test.err {
+				t.Fatalf("got error %v, want error %v", err, test.err)
+			}
+
+			if got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
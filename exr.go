@@ -0,0 +1,155 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	ErrInvalidEXR = errors.New("invalid openexr header")
+)
+
+const (
+	exrMagic = "\x76\x2f\x31\x01"
+
+	// maxEXRAttribute bounds the size of a single header attribute; large
+	// attributes such as previews are skipped rather than read.
+	maxEXRAttribute = 1 << 24
+
+	// maxEXRString bounds the length of attribute names and types.
+	maxEXRString = 256
+)
+
+type exrBox struct {
+	xMin, yMin, xMax, yMax int32
+}
+
+func (b exrBox) width() int {
+	return int(b.xMax) - int(b.xMin) + 1
+}
+
+func (b exrBox) height() int {
+	return int(b.yMax) - int(b.yMin) + 1
+}
+
+// readNullTerminated reads an attribute name or type, which is terminated by
+// a null byte and may be at most 256 bytes long.
+func readNullTerminated(r io.Reader) (string, error) {
+	lr := io.LimitReader(r, maxEXRString+1)
+
+	var s []byte
+
+	c := make([]byte, 1)
+
+	for {
+		if _, err := io.ReadFull(lr, c); err != nil {
+			if len(s) > maxEXRString {
+				return "", ErrInvalidEXR
+			}
+
+			return "", err
+		}
+
+		if c[0] == 0 {
+			return string(s), nil
+		}
+
+		s = append(s, c[0])
+	}
+}
+
+// exrDimensions reads the displayWindow and dataWindow attributes from the
+// header of an OpenEXR file (or the first part of a multi-part file). The
+// display window gives the dimensions, and the data window is noted when it
+// differs.
+func exrDimensions(r io.ReadSeeker, data *imageData) error {
+	br := bufio.NewReader(r)
+
+	var preamble [8]byte
+
+	if _, err := io.ReadFull(br, preamble[:]); err != nil {
+		return err
+	}
+
+	if string(preamble[:4]) != exrMagic {
+		return ErrInvalidEXR
+	}
+
+	var display, dataWindow exrBox
+	var hasDisplay, hasData bool
+
+	for !hasDisplay || !hasData {
+		name, err := readNullTerminated(br)
+		if err != nil {
+			return err
+		}
+
+		// An empty name ends the header.
+		if name == "" {
+			break
+		}
+
+		kind, err := readNullTerminated(br)
+		if err != nil {
+			return err
+		}
+
+		var sizeBytes [4]byte
+
+		if _, err := io.ReadFull(br, sizeBytes[:]); err != nil {
+			return err
+		}
+
+		size := binary.LittleEndian.Uint32(sizeBytes[:])
+		if size > maxEXRAttribute {
+			return ErrInvalidEXR
+		}
+
+		if kind != "box2i" || size != 16 || (name != "displayWindow" && name != "dataWindow") {
+			if _, err := br.Discard(int(size)); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		var raw [16]byte
+
+		if _, err := io.ReadFull(br, raw[:]); err != nil {
+			return err
+		}
+
+		b := exrBox{
+			xMin: int32(binary.LittleEndian.Uint32(raw[0:])),
+			yMin: int32(binary.LittleEndian.Uint32(raw[4:])),
+			xMax: int32(binary.LittleEndian.Uint32(raw[8:])),
+			yMax: int32(binary.LittleEndian.Uint32(raw[12:])),
+		}
+
+		if name == "displayWindow" {
+			display, hasDisplay = b, true
+		} else {
+			dataWindow, hasData = b, true
+		}
+	}
+
+	if !hasDisplay || display.width() <= 0 || display.height() <= 0 {
+		return ErrInvalidEXR
+	}
+
+	data.width, data.height = display.width(), display.height()
+
+	if hasData && dataWindow != display {
+		data.notes = append(data.notes, fmt.Sprintf("data window %dx%d at (%d,%d)",
+			dataWindow.width(), dataWindow.height(), dataWindow.xMin, dataWindow.yMin))
+	}
+
+	return nil
+}
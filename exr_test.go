@@ -0,0 +1,111 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func testEXRAttribute(name, kind string, value []byte) []byte {
+	out := append([]byte(name+"\x00"+kind+"\x00"), binary.LittleEndian.AppendUint32(nil, uint32(len(value)))...)
+
+	return append(out, value...)
+}
+
+func testEXRBox(xMin, yMin, xMax, yMax int32) []byte {
+	var out []byte
+
+	for _, v := range []int32{xMin, yMin, xMax, yMax} {
+		out = binary.LittleEndian.AppendUint32(out, uint32(v))
+	}
+
+	return out
+}
+
+func testEXR(attributes ...[]byte) []byte {
+	out := []byte(exrMagic + "\x02\x00\x00\x00")
+
+	for _, attribute := range attributes {
+		out = append(out, attribute...)
+	}
+
+	return append(out, 0)
+}
+
+func TestExrDimensions(t *testing.T) {
+	channels := testEXRAttribute("channels", "chlist", make([]byte, 37))
+	display := testEXRAttribute("displayWindow", "box2i", testEXRBox(0, 0, 1919, 1079))
+
+	tests := []struct {
+		name   string
+		input  []byte
+		width  int
+		height int
+		notes  []string
+		err    bool
+	}{
+		{"display window", testEXR(channels, display), 1920, 1080, nil, false},
+		{"matching data window", testEXR(testEXRAttribute("dataWindow", "box2i", testEXRBox(0, 0, 1919, 1079)), display), 1920, 1080, nil, false},
+		{"cropped data window", testEXR(testEXRAttribute("dataWindow", "box2i", testEXRBox(10, 20, 109, 69)), display), 1920, 1080, []string{"data window 100x50 at (10,20)"}, false},
+		{"no display window", testEXR(channels), 0, 0, nil, true},
+		{"truncated", testEXR(channels, display)[:40], 0, 0, nil, true},
+		{"unterminated name", append([]byte(exrMagic+"\x02\x00\x00\x00"), strings.Repeat("a", 1000)...), 0, 0, nil, true},
+		{"bad magic", []byte("\x76\x2f\x31\x02\x02\x00\x00\x00"), 0, 0, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var data imageData
+
+			err := exrDimensions(bytes.NewReader(test.input), &data)
+			if test.err {
+				if err == nil {
+					t.Errorf("got %dx%d, want error", data.width, data.height)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if data.width != test.width || data.height != test.height || !slices.Equal(data.notes, test.notes) {
+				t.Errorf("got %dx%d %q, want %dx%d %q", data.width, data.height, data.notes, test.width, test.height, test.notes)
+			}
+		})
+	}
+}
+
+func TestReadNullTerminated(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		err   error
+	}{
+		{"displayWindow\x00box2i\x00", "displayWindow", nil},
+		{"\x00", "", nil},
+		{strings.Repeat("a", 256) + "\x00", strings.Repeat("a", 256), nil},
+		{strings.Repeat("a", 257) + "\x00", "", ErrInvalidEXR},
+	}
+
+	for _, test := range tests {
+		r := strings.NewReader(test.input)
+
+		got, err := readNullTerminated(r)
+		if got != test.want || err != test.err {
+			t.Errorf("readNullTerminated(%.20q...) = %q, %v, want %q, %v", test.input, got, err, test.want, test.err)
+		}
+
+		// The reader must not be consumed beyond the limit.
+		if test.err != nil && r.Len() != len(test.input)-maxEXRString-1 {
+			t.Errorf("read %d bytes, want %d", len(test.input)-r.Len(), maxEXRString+1)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !unix
+
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"os"
+)
+
+// dirKey identifies a directory by its path with all symlinks resolved, as
+// device and inode numbers are not available on this platform.
+func dirKey(path string, _ os.FileInfo) any {
+	return resolvedKey(path)
+}
@@ -0,0 +1,26 @@
+//go:build unix
+
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+type fileKey struct {
+	dev, ino uint64
+}
+
+// dirKey identifies a directory by its device and inode numbers, which are
+// the same however the directory is reached.
+func dirKey(path string, info os.FileInfo) any {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return fileKey{dev: uint64(st.Dev), ino: uint64(st.Ino)}
+	}
+
+	return resolvedKey(path)
+}
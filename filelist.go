@@ -0,0 +1,51 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+)
+
+// readFileList reads the paths listed in source, or on stdin if source is
+// "-". Paths are separated by NUL characters if any are present, as written
+// by find -print0 and similar, or by newlines otherwise.
+func readFileList(source string) ([]string, error) {
+	var r io.Reader = os.Stdin
+
+	if source != "-" {
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		r = f
+	}
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	separator := "\n"
+	if bytes.IndexByte(content, 0) != -1 {
+		separator = "\x00"
+	}
+
+	var paths []string
+
+	for _, path := range strings.Split(string(content), separator) {
+		path = strings.TrimSuffix(path, "\r")
+
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths, nil
+}
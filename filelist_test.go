@@ -0,0 +1,79 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReadFileList(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"newlines", "a.png\nb c.jpg\n", []string{"a.png", "b c.jpg"}},
+		{"crlf", "a.png\r\nb.jpg\r\n", []string{"a.png", "b.jpg"}},
+		{"blank lines", "\na.png\n\n\nb.jpg", []string{"a.png", "b.jpg"}},
+		{"nul separated", "a.png\x00new\nline.jpg\x00", []string{"a.png", "new\nline.jpg"}},
+		{"empty", "", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			source := filepath.Join(t.TempDir(), "list")
+
+			if err := os.WriteFile(source, []byte(test.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := readFileList(source)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReadFileListStdin(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "list")
+
+	if err := os.WriteFile(source, []byte("a.png\nb.jpg\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	saved := os.Stdin
+	t.Cleanup(func() { os.Stdin = saved })
+
+	os.Stdin = f
+
+	got, err := readFileList("-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"a.png", "b.jpg"}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestReadFileListMissing(t *testing.T) {
+	if _, err := readFileList(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("got no error for a missing list")
+	}
+}
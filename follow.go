@@ -0,0 +1,64 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// A dirChain holds the directories above the one being walked while
+// following symlinks. As with find -L, a link back to one of them is a loop
+// and is not entered, while a directory reached through several links
+// elsewhere is walked once for each.
+type dirChain struct {
+	key    any
+	parent *dirChain
+}
+
+// enter returns c extended with the directory at path, or false if that
+// directory is already in c. Directories which cannot be examined are left
+// for walkPath to report.
+func (c *dirChain) enter(path string) (*dirChain, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return c, true
+	}
+
+	key := dirKey(path, info)
+
+	for ancestor := c; ancestor != nil; ancestor = ancestor.parent {
+		if ancestor.key == key {
+			return c, false
+		}
+	}
+
+	return &dirChain{key: key, parent: c}, true
+}
+
+func resolvedKey(path string) string {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return path
+	}
+
+	return resolved
+}
+
+// linkTarget returns the path that path resolves to if it is or passes
+// through a symlink, or an empty string otherwise.
+func linkTarget(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return ""
+	}
+
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil || resolved == abs {
+		return ""
+	}
+
+	return resolved
+}
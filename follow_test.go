@@ -0,0 +1,93 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestScanFollow(t *testing.T) {
+	withFlagDefaults(t)
+
+	root := t.TempDir()
+	outside := t.TempDir()
+
+	for _, path := range []string{filepath.Join(root, "d1", "a.png"), filepath.Join(outside, "b.png")} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, testPNG(t, 10, 10), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	links := map[string]string{
+		filepath.Join(root, "d1", "loop"): root,
+		filepath.Join(root, "outside"):    outside,
+		filepath.Join(root, "c.png"):      filepath.Join(outside, "b.png"),
+	}
+
+	for link, target := range links {
+		if err := os.Symlink(target, link); err != nil {
+			t.Skip(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		follow bool
+		want   []string
+	}{
+		{"not following", false, []string{"c.png", "d1/a.png"}},
+		{"following", true, []string{"c.png", "d1/a.png", "outside/b.png"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			savedRecursive, savedFollow := recursive, follow
+
+			t.Cleanup(func() {
+				recursive, follow = savedRecursive, savedFollow
+			})
+
+			recursive, follow = true, test.follow
+
+			if got := testScan(t, root); !slices.Equal(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDirChain(t *testing.T) {
+	root := t.TempDir()
+	child := filepath.Join(root, "child")
+
+	if err := os.Mkdir(child, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	chain, ok := (*dirChain)(nil).enter(root)
+	if !ok {
+		t.Fatal("could not enter root")
+	}
+
+	chain, ok = chain.enter(child)
+	if !ok {
+		t.Fatal("could not enter child")
+	}
+
+	if _, ok := chain.enter(root); ok {
+		t.Error("entered an ancestor")
+	}
+
+	if _, ok := chain.enter(child); ok {
+		t.Error("entered the current directory")
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+var (
+	ErrInvalidPattern = errors.New("invalid glob pattern")
+)
+
+// validPatterns checks the syntax of every --include, --exclude and
+// --exclude-dir pattern up front, so that a typo is not silently treated as
+// matching nothing.
+func validPatterns() error {
+	for _, patterns := range [][]string{includes, excludes, excludeDirs} {
+		for _, pattern := range patterns {
+			if !doublestar.ValidatePattern(pattern) {
+				return fmt.Errorf("%w: %s", ErrInvalidPattern, pattern)
+			}
+		}
+	}
+
+	return nil
+}
+
+// relativePath returns path relative to root, using forward slashes as
+// doublestar expects.
+func relativePath(root, fullPath string) string {
+	rel, err := filepath.Rel(root, fullPath)
+	if err != nil {
+		return filepath.ToSlash(fullPath)
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// matchesAny reports whether rel matches any of patterns. As with
+// .gitignore, patterns without a slash match the name of an entry in any
+// directory, while those with one match its path relative to the root.
+func matchesAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		target := rel
+		if !strings.Contains(pattern, "/") {
+			target = path.Base(rel)
+		}
+
+		if matched, _ := doublestar.Match(pattern, target); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// skipDir reports whether the directory at rel should not be descended into.
+func skipDir(rel string) bool {
+	return matchesAny(excludeDirs, rel) || matchesAny(excludes, rel)
+}
+
+// skipFile reports whether the file at rel should not be opened.
+func skipFile(rel string) bool {
+	return (len(includes) > 0 && !matchesAny(includes, rel)) || matchesAny(excludes, rel)
+}
@@ -0,0 +1,118 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// withPatterns sets the glob filter flags, restoring their previous values
+// once t finishes.
+func withPatterns(t *testing.T, include, exclude, excludeDir []string) {
+	t.Helper()
+
+	savedIncludes, savedExcludes, savedExcludeDirs := includes, excludes, excludeDirs
+
+	t.Cleanup(func() {
+		includes, excludes, excludeDirs = savedIncludes, savedExcludes, savedExcludeDirs
+	})
+
+	includes, excludes, excludeDirs = include, exclude, excludeDir
+}
+
+func TestMatchesAny(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		rel      string
+		want     bool
+	}{
+		{[]string{"*.png"}, "a/b/c.png", true},
+		{[]string{"*.png"}, "a/b/c.jpg", false},
+		{[]string{"a/*.png"}, "a/c.png", true},
+		{[]string{"a/*.png"}, "a/b/c.png", false},
+		{[]string{"a/**/*.png"}, "a/b/c.png", true},
+		{[]string{"**/thumbs/**"}, "x/thumbs/y.png", true},
+		{[]string{"*.{png,jpg}"}, "c.jpg", true},
+		{[]string{"*.gif", "*.jpg"}, "c.jpg", true},
+		{nil, "c.jpg", false},
+	}
+
+	for _, test := range tests {
+		if got := matchesAny(test.patterns, test.rel); got != test.want {
+			t.Errorf("matchesAny(%q, %q) = %v, want %v", test.patterns, test.rel, got, test.want)
+		}
+	}
+}
+
+func TestSkipFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		rel     string
+		want    bool
+	}{
+		{"no patterns", nil, nil, "a.png", false},
+		{"included", []string{"*.png"}, nil, "a.png", false},
+		{"not included", []string{"*.png"}, nil, "a.jpg", true},
+		{"excluded", nil, []string{"*.png"}, "a.png", true},
+		{"excluded wins", []string{"*.png"}, []string{"raw/**"}, "raw/a.png", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			withPatterns(t, test.include, test.exclude, nil)
+
+			if got := skipFile(test.rel); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSkipDir(t *testing.T) {
+	withPatterns(t, []string{"*.png"}, []string{"tmp"}, []string{"node_modules", "build/cache"})
+
+	tests := []struct {
+		rel  string
+		want bool
+	}{
+		{"src", false},
+		{"src/node_modules", true},
+		{"build/cache", true},
+		{"src/build/cache", false},
+		{"tmp", true},
+	}
+
+	for _, test := range tests {
+		if got := skipDir(test.rel); got != test.want {
+			t.Errorf("skipDir(%q) = %v, want %v", test.rel, got, test.want)
+		}
+	}
+}
+
+func TestValidPatterns(t *testing.T) {
+	withPatterns(t, []string{"*.png"}, []string{"a/**"}, []string{"b"})
+
+	if err := validPatterns(); err != nil {
+		t.Errorf("got error %v for valid patterns", err)
+	}
+
+	withPatterns(t, nil, []string{"[abc"}, nil)
+
+	if err := validPatterns(); !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("got error %v, want %v", err, ErrInvalidPattern)
+	}
+}
+
+func TestRelativePath(t *testing.T) {
+	root := filepath.Join("photos", "2024")
+
+	if got, want := relativePath(root, filepath.Join(root, "trip", "a.png")), "trip/a.png"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -3,9 +3,12 @@ module github.com/Seednode/imagesize
 go 1.26
 
 require (
+	github.com/bmatcuk/doublestar/v4 v4.9.1
 	github.com/gen2brain/avif v0.5.2
 	github.com/gen2brain/heic v0.6.1
 	github.com/gen2brain/jpegxl v0.5.2
+	github.com/klauspost/compress v1.18.0
+	github.com/nwaples/rardecode/v2 v2.2.0
 	github.com/spf13/cobra v1.10.2
 	golang.org/x/image v0.43.0
 )
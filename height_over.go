@@ -9,11 +9,16 @@ import (
 )
 
 var heightOverCmd = &cobra.Command{
-	Use:   "over <size in pixels> [directory1] ...[directoryN]",
+	Use:   "over <size in pixels> [path1] ...[pathN]",
 	Short: "Filter images by height",
 	Args:  cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		err := imageSizes(taller, args)
+		filter, err := thresholdFilter(heightField, greater, args[0])
+		if err != nil {
+			return err
+		}
+
+		err = imageSizes(cmd, filter, args[1:])
 		if err != nil {
 			return err
 		}
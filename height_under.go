@@ -9,11 +9,16 @@ import (
 )
 
 var heightUnderCmd = &cobra.Command{
-	Use:   "under <size in pixels> [directory1] ...[directoryN]",
+	Use:   "under <size in pixels> [path1] ...[pathN]",
 	Short: "Filter images by height",
 	Args:  cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		err := imageSizes(shorter, args)
+		filter, err := thresholdFilter(heightField, less, args[0])
+		if err != nil {
+			return err
+		}
+
+		err = imageSizes(cmd, filter, args[1:])
 		if err != nil {
 			return err
 		}
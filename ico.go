@@ -0,0 +1,149 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	ErrInvalidIcon      = errors.New("invalid icon directory")
+	ErrInvalidIconEntry = errors.New("icon entry must be one of largest, smallest, or all")
+)
+
+var iconEntryChoices = []string{"largest", "smallest", "all"}
+
+const (
+	iconHeaderLength = 6
+	iconEntryLength  = 16
+)
+
+// isIcon reports whether header looks like the start of an ICO or CUR file.
+// The signature is short, so the first directory entry is checked as well.
+func isIcon(header []byte) bool {
+	if len(header) < iconHeaderLength+iconEntryLength {
+		return false
+	}
+
+	kind := binary.LittleEndian.Uint16(header[2:])
+	count := binary.LittleEndian.Uint16(header[4:])
+
+	return binary.LittleEndian.Uint16(header) == 0 &&
+		(kind == 1 || kind == 2) &&
+		count > 0 &&
+		header[iconHeaderLength+3] == 0
+}
+
+// validIconEntry reports whether the value of --icon-entry is recognised.
+func validIconEntry() bool {
+	for _, choice := range iconEntryChoices {
+		if iconEntry == choice {
+			return true
+		}
+	}
+
+	return false
+}
+
+// iconDimensions reads the size of every image in an ICO or CUR directory,
+// then reports the largest or smallest of them, or all of them as separate
+// results (e.g. favicon.ico#16x16), depending on --icon-entry.
+func iconDimensions(r io.ReadSeeker, data *imageData) error {
+	var header [iconHeaderLength]byte
+
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return err
+	}
+
+	count := int(binary.LittleEndian.Uint16(header[4:]))
+
+	directory := make([]byte, count*iconEntryLength)
+
+	if _, err := io.ReadFull(r, directory); err != nil {
+		return err
+	}
+
+	var entries []subimage
+
+	seen := make(map[string]int)
+
+	for i := range count {
+		entry := directory[i*iconEntryLength : (i+1)*iconEntryLength]
+
+		// Sizes are stored in a single byte, with 0 meaning 256.
+		width, height := int(entry[0]), int(entry[1])
+		if width == 0 {
+			width = 256
+		}
+
+		if height == 0 {
+			height = 256
+		}
+
+		// PNG-compressed entries may exceed 256 pixels, so their own header
+		// takes precedence over the directory.
+		offset := int64(binary.LittleEndian.Uint32(entry[12:]))
+
+		if pngWidth, pngHeight, ok := iconPNGSize(r, offset); ok {
+			width, height = pngWidth, pngHeight
+		}
+
+		suffix := fmt.Sprintf("%dx%d", width, height)
+
+		seen[suffix]++
+		if seen[suffix] > 1 {
+			suffix = fmt.Sprintf("%s-%d", suffix, seen[suffix])
+		}
+
+		entries = append(entries, subimage{suffix: suffix, width: width, height: height})
+	}
+
+	if len(entries) == 0 {
+		return ErrInvalidIcon
+	}
+
+	chosen := entries[0]
+
+	for _, entry := range entries[1:] {
+		area := entry.width * entry.height
+
+		switch {
+		case iconEntry == "smallest" && area < chosen.width*chosen.height,
+			iconEntry != "smallest" && area > chosen.width*chosen.height:
+			chosen = entry
+		}
+	}
+
+	data.width, data.height = chosen.width, chosen.height
+
+	if iconEntry == "all" {
+		data.subimages = entries
+	}
+
+	return nil
+}
+
+// iconPNGSize reads the dimensions of a PNG-compressed icon entry from its
+// IHDR chunk, reporting false if the entry is not a PNG.
+func iconPNGSize(r io.ReadSeeker, offset int64) (int, int, bool) {
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return 0, 0, false
+	}
+
+	var header [24]byte
+
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, 0, false
+	}
+
+	if string(header[:8]) != pngSignature || string(header[12:16]) != "IHDR" {
+		return 0, 0, false
+	}
+
+	return int(binary.BigEndian.Uint32(header[16:])), int(binary.BigEndian.Uint32(header[20:])), true
+}
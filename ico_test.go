@@ -0,0 +1,113 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// testIcon assembles an ICO file with one directory entry per size, where a
+// size of 0 stands for 256. Entries larger than 256 pixels are stored as PNG.
+func testIcon(sizes ...int) []byte {
+	out := []byte{0, 0, 1, 0}
+	out = binary.LittleEndian.AppendUint16(out, uint16(len(sizes)))
+
+	var images []byte
+
+	for _, size := range sizes {
+		offset := iconHeaderLength + iconEntryLength*len(sizes) + len(images)
+
+		entry := make([]byte, iconEntryLength)
+		entry[0], entry[1] = byte(size), byte(size)
+		binary.LittleEndian.PutUint32(entry[12:], uint32(offset))
+		out = append(out, entry...)
+
+		image := make([]byte, 40)
+		if size > 256 {
+			image = append([]byte(pngSignature+"\x00\x00\x00\x0dIHDR"), make([]byte, 8)...)
+			binary.BigEndian.PutUint32(image[16:], uint32(size))
+			binary.BigEndian.PutUint32(image[20:], uint32(size))
+		}
+
+		images = append(images, image...)
+	}
+
+	return append(out, images...)
+}
+
+func TestIconDimensions(t *testing.T) {
+	icon := testIcon(16, 32, 0, 512)
+
+	tests := []struct {
+		name    string
+		input   []byte
+		entry   string
+		width   int
+		height  int
+		entries int
+		err     bool
+	}{
+		{"largest", icon, "largest", 512, 512, 0, false},
+		{"smallest", icon, "smallest", 16, 16, 0, false},
+		{"all", icon, "all", 512, 512, 4, false},
+		{"256 pixels", testIcon(48, 0), "largest", 256, 256, 0, false},
+		{"truncated directory", icon[:20], "largest", 0, 0, 0, true},
+		{"empty directory", testIcon(), "largest", 0, 0, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			saved := iconEntry
+			t.Cleanup(func() { iconEntry = saved })
+
+			iconEntry = test.entry
+
+			var data imageData
+
+			err := iconDimensions(bytes.NewReader(test.input), &data)
+			if test.err {
+				if err == nil {
+					t.Errorf("got %dx%d, want error", data.width, data.height)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if data.width != test.width || data.height != test.height {
+				t.Errorf("got %dx%d, want %dx%d", data.width, data.height, test.width, test.height)
+			}
+
+			if len(data.subimages) != test.entries {
+				t.Errorf("got %d entries, want %d", len(data.subimages), test.entries)
+			}
+		})
+	}
+}
+
+func TestIsIcon(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   bool
+	}{
+		{"icon", testIcon(16), true},
+		{"cursor", append([]byte{0, 0, 2, 0}, testIcon(16)[4:]...), true},
+		{"no entries", testIcon(), false},
+		{"bad kind", append([]byte{0, 0, 3, 0}, testIcon(16)[4:]...), false},
+		{"truncated", testIcon(16)[:10], false},
+	}
+
+	for _, test := range tests {
+		if got := isIcon(test.header); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// ignoreFiles lists the ignore files read from each directory, in order of
+// increasing precedence.
+var ignoreFiles = []string{".gitignore", ".ignore", ".imagesizeignore"}
+
+type ignoreRule struct {
+	pattern string
+	negate  bool
+	dirOnly bool
+}
+
+// An ignoreList holds the rules read from the ignore files of a directory,
+// linked to those of its parent directories. Rules read from above the
+// directory being scanned are matched against paths relative to it, joined
+// to prefix. As with git, .gitignore files are only read within a
+// repository.
+type ignoreList struct {
+	dir        string
+	prefix     string
+	rules      []ignoreRule
+	repository bool
+	parent     *ignoreList
+}
+
+// ignoring reports whether ignore files are honoured, which they are by
+// default when scanning recursively.
+func ignoring() bool {
+	return recursive && !noIgnore
+}
+
+// parseIgnoreLine converts a line of an ignore file into a rule, following
+// the rules of .gitignore. Patterns containing a slash other than at the end
+// are anchored to the directory containing the ignore file, while others
+// match at any depth below it.
+func parseIgnoreLine(line string) (ignoreRule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+
+	// Trailing spaces are ignored unless escaped.
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	var rule ignoreRule
+
+	switch {
+	case strings.HasPrefix(line, "!"):
+		rule.negate = true
+		line = line[1:]
+	case strings.HasPrefix(line, "\\!"), strings.HasPrefix(line, "\\#"):
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	line = escapeBraces(line)
+
+	if !anchored {
+		line = "**/" + line
+	}
+
+	if !doublestar.ValidatePattern(line) {
+		return ignoreRule{}, false
+	}
+
+	rule.pattern = line
+
+	return rule, true
+}
+
+// escapeBraces escapes any unescaped braces in pattern, which are literal in
+// .gitignore but denote alternatives to doublestar.
+func escapeBraces(pattern string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\\' && i+1 < len(pattern):
+			b.WriteByte(c)
+			i++
+			b.WriteByte(pattern[i])
+		case c == '{', c == '}':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+// readIgnoreFile reads the rules from the ignore file at path, if it exists.
+func readIgnoreFile(path string) []ignoreRule {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreLine(scanner.Text()); ok {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
+
+// readIgnoreFiles reads the rules from each of the ignore files in dir,
+// skipping .gitignore unless dir is within a repository.
+func readIgnoreFiles(dir string, repository bool) []ignoreRule {
+	var rules []ignoreRule
+
+	for _, name := range ignoreFiles {
+		if name == ".gitignore" && !repository {
+			continue
+		}
+
+		rules = append(rules, readIgnoreFile(filepath.Join(dir, name))...)
+	}
+
+	return rules
+}
+
+// parentIgnores returns the rules of the ignore files above the directory at
+// root, up to the top of the repository containing it, as git would apply
+// them, and whether root itself or a directory above it is ignored by them.
+// Outside of a repository, only the ignore files within root apply.
+func parentIgnores(root string) (*ignoreList, bool) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, false
+	}
+
+	var parents []string
+
+	for dir := abs; !isRepository(dir); {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, false
+		}
+
+		dir = parent
+		parents = append(parents, dir)
+	}
+
+	// Each directory from the top of the repository down to root is checked
+	// against the rules above it, as git does not descend into ignored
+	// directories.
+	var l *ignoreList
+
+	for _, dir := range slices.Backward(parents) {
+		if l.ignored(dir, true) {
+			return nil, true
+		}
+
+		l = l.load(dir)
+	}
+
+	if l.ignored(abs, true) {
+		return nil, true
+	}
+
+	// The rules are then rebased onto root, as the paths being scanned are
+	// relative to it.
+	for n := l; n != nil; n = n.parent {
+		prefix, err := filepath.Rel(n.dir, abs)
+		if err != nil {
+			return nil, false
+		}
+
+		n.dir, n.prefix = root, filepath.ToSlash(prefix)
+	}
+
+	return l, false
+}
+
+// isRepository reports whether dir is the top of a git repository.
+func isRepository(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+
+	return err == nil
+}
+
+// inRepository reports whether the directory l belongs to is within a git
+// repository.
+func (l *ignoreList) inRepository() bool {
+	return l != nil && l.repository
+}
+
+// load returns the rules for the directory at dir, which is a child of the
+// directory l belongs to. If dir has no ignore files and does not start a
+// repository, l itself is returned.
+func (l *ignoreList) load(dir string) *ignoreList {
+	repository := l.inRepository() || isRepository(dir)
+
+	rules := readIgnoreFiles(dir, repository)
+	if len(rules) == 0 && repository == l.inRepository() {
+		return l
+	}
+
+	return &ignoreList{dir: dir, rules: rules, repository: repository, parent: l}
+}
+
+// ignored reports whether the entry at path is ignored. The rules of deeper
+// directories take precedence over those of their parents, and within a
+// directory, later rules take precedence over earlier ones, so that negated
+// patterns can re-include entries.
+func (l *ignoreList) ignored(path string, isDir bool) bool {
+	if isDir && filepath.Base(path) == ".git" {
+		return true
+	}
+
+	for ; l != nil; l = l.parent {
+		rel := relativePath(l.dir, path)
+		if rel == ".." || strings.HasPrefix(rel, "../") {
+			continue
+		}
+
+		switch {
+		case rel == "." && l.prefix == "":
+			continue
+		case rel == ".":
+			rel = l.prefix
+		case l.prefix != "":
+			rel = l.prefix + "/" + rel
+		}
+
+		for i := len(l.rules) - 1; i >= 0; i-- {
+			rule := l.rules[i]
+
+			if rule.dirOnly && !isDir {
+				continue
+			}
+
+			if matched, _ := doublestar.Match(rule.pattern, rel); matched {
+				return !rule.negate
+			}
+		}
+	}
+
+	return false
+}
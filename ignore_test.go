@@ -0,0 +1,241 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testIgnoreList builds an ignore list for dir from the lines of an ignore
+// file.
+func testIgnoreList(parent *ignoreList, dir string, lines ...string) *ignoreList {
+	l := &ignoreList{dir: dir, parent: parent}
+
+	for _, line := range lines {
+		if rule, ok := parseIgnoreLine(line); ok {
+			l.rules = append(l.rules, rule)
+		}
+	}
+
+	return l
+}
+
+func TestIgnored(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"basename at root", []string{"*.jpg"}, "repo/a.jpg", false, true},
+		{"basename at depth", []string{"*.jpg"}, "repo/x/y/a.jpg", false, true},
+		{"no match", []string{"*.jpg"}, "repo/a.png", false, false},
+		{"comment", []string{"#a.png"}, "repo/#a.png", false, false},
+		{"escaped hash", []string{"\\#a.png"}, "repo/#a.png", false, true},
+		{"escaped bang", []string{"\\!a.png"}, "repo/!a.png", false, true},
+		{"trailing spaces", []string{"a.png   "}, "repo/a.png", false, true},
+		{"escaped trailing space", []string{"a.png\\ "}, "repo/a.png ", false, true},
+		{"crlf", []string{"a.png\r"}, "repo/a.png", false, true},
+		{"negation", []string{"*.png", "!keep.png"}, "repo/keep.png", false, false},
+		{"negation overridden", []string{"!keep.png", "*.png"}, "repo/keep.png", false, true},
+		{"leading slash anchors", []string{"/a.png"}, "repo/x/a.png", false, false},
+		{"leading slash at root", []string{"/a.png"}, "repo/a.png", false, true},
+		{"middle slash anchors", []string{"x/a.png"}, "repo/y/x/a.png", false, false},
+		{"middle slash at root", []string{"x/a.png"}, "repo/x/a.png", false, true},
+		{"directory only matches directory", []string{"build/"}, "repo/x/build", true, true},
+		{"directory only skips file", []string{"build/"}, "repo/build", false, false},
+		{"leading double star", []string{"**/cache"}, "repo/a/b/cache", true, true},
+		{"middle double star", []string{"a/**/b.png"}, "repo/a/x/y/b.png", false, true},
+		{"middle double star, no directories", []string{"a/**/b.png"}, "repo/a/b.png", false, true},
+		{"trailing double star", []string{"a/**"}, "repo/a/x/b.png", false, true},
+		{"star does not cross slash", []string{"a/*.png"}, "repo/a/x/b.png", false, false},
+		{"character class", []string{"img[0-9].png"}, "repo/img5.png", false, true},
+		{"literal braces", []string{"{a,b}.png"}, "repo/{a,b}.png", false, true},
+		{"braces are not alternatives", []string{"{a,b}.png"}, "repo/a.png", false, false},
+		{"git directory", nil, "repo/x/.git", true, true},
+		{"git file", nil, "repo/x/.git", false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l := testIgnoreList(nil, "repo", test.lines...)
+
+			if got := l.ignored(test.path, test.isDir); got != test.want {
+				t.Errorf("ignored(%q) = %v, want %v", test.path, got, test.want)
+			}
+		})
+	}
+}
+
+func TestIgnoredPrecedence(t *testing.T) {
+	root := testIgnoreList(nil, "repo", "*.png", "/top.jpg")
+	sub := testIgnoreList(root, "repo/sub", "!keep.png", "/top.jpg")
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"repo/keep.png", true},
+		{"repo/sub/keep.png", false},
+		{"repo/sub/deeper/keep.png", false},
+		{"repo/sub/other.png", true},
+		{"repo/top.jpg", true},
+		{"repo/sub/top.jpg", true},
+		{"repo/sub/deeper/top.jpg", false},
+	}
+
+	for _, test := range tests {
+		if got := sub.ignored(test.path, false); got != test.want {
+			t.Errorf("ignored(%q) = %v, want %v", test.path, got, test.want)
+		}
+	}
+}
+
+func TestParentIgnores(t *testing.T) {
+	repo := t.TempDir()
+
+	write := func(path, content string) {
+		t.Helper()
+
+		path = filepath.Join(repo, path)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(".git/HEAD", "")
+	write(".gitignore", strings.Join([]string{"*.jpg", "/sub/deep/anchored.png", "/top.png"}, "\n"))
+	write("sub/.ignore", "!keep.jpg\n")
+	write("sub/deep/.imagesizeignore", "")
+
+	root := filepath.Join(repo, "sub", "deep")
+
+	l, ignored := parentIgnores(root)
+	if ignored {
+		t.Fatal("root is ignored")
+	}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"other.jpg", true},
+		{"keep.jpg", false},
+		{"anchored.png", true},
+		{"top.png", false},
+		{"plain.png", false},
+	}
+
+	for _, test := range tests {
+		if got := l.ignored(filepath.Join(root, test.name), false); got != test.want {
+			t.Errorf("ignored(%q) = %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	// Outside of a repository, ignore files above root are not read.
+	outside := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(outside, ".ignore"), []byte("*\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Mkdir(filepath.Join(outside, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if l, ignored := parentIgnores(filepath.Join(outside, "sub")); l != nil || ignored {
+		t.Errorf("got rules %v and ignored %v outside of a repository", l, ignored)
+	}
+}
+
+func TestParentIgnoresRoot(t *testing.T) {
+	repo := t.TempDir()
+
+	for _, dir := range []string{".git", "build/out", "photos/2024"} {
+		if err := os.MkdirAll(filepath.Join(repo, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(repo, ".gitignore"), []byte("build/\n/photos/2024\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		dir  string
+		want bool
+	}{
+		{".", false},
+		{"build", true},
+		{"build/out", true},
+		{"photos", false},
+		{"photos/2024", true},
+	}
+
+	for _, test := range tests {
+		if _, got := parentIgnores(filepath.Join(repo, test.dir)); got != test.want {
+			t.Errorf("%s: got ignored %v, want %v", test.dir, got, test.want)
+		}
+	}
+}
+
+func TestScanIgnores(t *testing.T) {
+	withFlagDefaults(t)
+
+	savedRecursive, savedNoIgnore := recursive, noIgnore
+
+	t.Cleanup(func() {
+		recursive, noIgnore = savedRecursive, savedNoIgnore
+	})
+
+	recursive, noIgnore = true, false
+
+	write := func(path string, content []byte) {
+		t.Helper()
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	root := t.TempDir()
+	image := testPNG(t, 10, 10)
+
+	write(filepath.Join(root, ".gitignore"), []byte("a.png\n"))
+	write(filepath.Join(root, ".ignore"), []byte("b.png\n"))
+	write(filepath.Join(root, "a.png"), image)
+	write(filepath.Join(root, "b.png"), image)
+	write(filepath.Join(root, "repo", ".git", "HEAD"), nil)
+	write(filepath.Join(root, "repo", ".gitignore"), []byte("c.png\n"))
+	write(filepath.Join(root, "repo", "c.png"), image)
+	write(filepath.Join(root, "repo", "d.png"), image)
+
+	// Outside of a repository, only .ignore applies.
+	if got, want := testScan(t, root), []string{"a.png", "repo/d.png"}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Scanning an ignored directory within a repository finds nothing.
+	write(filepath.Join(root, "repo", ".gitignore"), []byte("skipped/\n"))
+	write(filepath.Join(root, "repo", "skipped", "e.png"), image)
+
+	if got := testScan(t, filepath.Join(root, "repo", "skipped")); len(got) != 0 {
+		t.Errorf("got %q from an ignored directory", got)
+	}
+}
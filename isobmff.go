@@ -0,0 +1,343 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+var (
+	ErrInvalidBox    = errors.New("invalid isobmff box")
+	ErrNoPrimaryItem = errors.New("no dimensions found for primary item")
+)
+
+// maxMetaSize bounds the size of the meta box read into memory, which holds
+// only item metadata and is typically a few kilobytes.
+const maxMetaSize = 16 << 20
+
+type box struct {
+	kind string
+	data []byte
+}
+
+// nextBox reads a box header from the start of data, returning the box and
+// the remainder of data following it.
+func nextBox(data []byte) (box, []byte, error) {
+	if len(data) < 8 {
+		return box{}, nil, ErrInvalidBox
+	}
+
+	size := uint64(binary.BigEndian.Uint32(data))
+	kind := string(data[4:8])
+	headerSize := uint64(8)
+
+	switch size {
+	case 0:
+		size = uint64(len(data))
+	case 1:
+		if len(data) < 16 {
+			return box{}, nil, ErrInvalidBox
+		}
+
+		size = binary.BigEndian.Uint64(data[8:])
+		headerSize = 16
+	}
+
+	if size < headerSize || size > uint64(len(data)) {
+		return box{}, nil, ErrInvalidBox
+	}
+
+	return box{kind: kind, data: data[headerSize:size]}, data[size:], nil
+}
+
+// children splits the payload of a container box into its child boxes.
+func children(data []byte) ([]box, error) {
+	var boxes []box
+
+	for len(data) > 0 {
+		b, rest, err := nextBox(data)
+		if err != nil {
+			return nil, err
+		}
+
+		boxes = append(boxes, b)
+		data = rest
+	}
+
+	return boxes, nil
+}
+
+// readBoxHeader reads the header of the next top-level box from r, returning
+// its kind and the size of its payload, or -1 if it extends to the end of r.
+func readBoxHeader(r io.Reader) (string, int64, error) {
+	var header [16]byte
+
+	if _, err := io.ReadFull(r, header[:8]); err != nil {
+		return "", 0, err
+	}
+
+	kind := string(header[4:8])
+	size := int64(binary.BigEndian.Uint32(header[:]))
+	headerSize := int64(8)
+
+	switch size {
+	case 0:
+		return kind, -1, nil
+	case 1:
+		if _, err := io.ReadFull(r, header[8:16]); err != nil {
+			return "", 0, err
+		}
+
+		size = int64(binary.BigEndian.Uint64(header[8:]))
+		headerSize = 16
+	}
+
+	if size < headerSize {
+		return "", 0, ErrInvalidBox
+	}
+
+	return kind, size - headerSize, nil
+}
+
+// findTopLevelBox scans the top-level boxes of r for the first box of the
+// given kind and returns its payload, without reading the boxes it skips.
+func findTopLevelBox(r io.ReadSeeker, kind string, limit int64) ([]byte, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	for {
+		boxKind, size, err := readBoxHeader(r)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case boxKind == kind && size == -1:
+			return io.ReadAll(io.LimitReader(r, limit))
+		case boxKind == kind && size > limit:
+			return nil, ErrInvalidBox
+		case boxKind == kind:
+			payload := make([]byte, size)
+
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return nil, err
+			}
+
+			return payload, nil
+		case size == -1:
+			return nil, io.EOF
+		}
+
+		if _, err := r.Seek(size, io.SeekCurrent); err != nil {
+			return nil, err
+		}
+	}
+}
+
+type itemProperties struct {
+	width, height int
+	rotation      int
+	mirrored      bool
+	hasSize       bool
+}
+
+// isobmffDimensions reads the dimensions of the primary item of a HEIF
+// container (AVIF or HEIC) from its ispe property. Unlike EXIF orientation,
+// an irot transform is part of how the item is displayed, so the width and
+// height are swapped for rotations of 90 and 270 degrees.
+func isobmffDimensions(r io.ReadSeeker, data *imageData) error {
+	meta, err := findTopLevelBox(r, "meta", maxMetaSize)
+	if err != nil {
+		return err
+	}
+
+	// meta is a full box, so its children follow the version and flags.
+	if len(meta) < 4 {
+		return ErrInvalidBox
+	}
+
+	boxes, err := children(meta[4:])
+	if err != nil {
+		return err
+	}
+
+	var primary uint32
+	var hasPrimary bool
+	var properties []box
+	var associations []byte
+
+	for _, b := range boxes {
+		switch b.kind {
+		case "pitm":
+			primary, err = parsePitm(b.data)
+			if err != nil {
+				return err
+			}
+
+			hasPrimary = true
+		case "iprp":
+			iprp, err := children(b.data)
+			if err != nil {
+				return err
+			}
+
+			for _, child := range iprp {
+				switch child.kind {
+				case "ipco":
+					properties, err = children(child.data)
+					if err != nil {
+						return err
+					}
+				case "ipma":
+					associations = child.data
+				}
+			}
+		}
+	}
+
+	if !hasPrimary || associations == nil {
+		return ErrNoPrimaryItem
+	}
+
+	indices, err := associatedProperties(associations, primary)
+	if err != nil {
+		return err
+	}
+
+	var item itemProperties
+
+	for _, index := range indices {
+		if index == 0 || index > len(properties) {
+			continue
+		}
+
+		property := properties[index-1]
+
+		switch property.kind {
+		case "ispe":
+			if len(property.data) < 12 {
+				return ErrInvalidBox
+			}
+
+			item.width = int(binary.BigEndian.Uint32(property.data[4:]))
+			item.height = int(binary.BigEndian.Uint32(property.data[8:]))
+			item.hasSize = true
+		case "irot":
+			if len(property.data) < 1 {
+				return ErrInvalidBox
+			}
+
+			item.rotation = int(property.data[0]&0x03) * 90
+		case "imir":
+			item.mirrored = true
+		}
+	}
+
+	if !item.hasSize || item.width == 0 || item.height == 0 {
+		return ErrNoPrimaryItem
+	}
+
+	data.width, data.height = item.width, item.height
+
+	if item.rotation == 90 || item.rotation == 270 {
+		data.width, data.height = data.height, data.width
+	}
+
+	// Any EXIF orientation of a transformed item describes the transform
+	// already applied, and must not be applied again.
+	if item.rotation != 0 || item.mirrored {
+		data.orientation = 1
+	}
+
+	return nil
+}
+
+func parsePitm(data []byte) (uint32, error) {
+	if len(data) < 4 {
+		return 0, ErrInvalidBox
+	}
+
+	if data[0] == 0 {
+		if len(data) < 6 {
+			return 0, ErrInvalidBox
+		}
+
+		return uint32(binary.BigEndian.Uint16(data[4:])), nil
+	}
+
+	if len(data) < 8 {
+		return 0, ErrInvalidBox
+	}
+
+	return binary.BigEndian.Uint32(data[4:]), nil
+}
+
+// associatedProperties returns the 1-based ipco indices of the properties
+// associated with an item, in the order listed by the ipma box.
+func associatedProperties(ipma []byte, item uint32) ([]int, error) {
+	if len(ipma) < 8 {
+		return nil, ErrInvalidBox
+	}
+
+	version, flags := ipma[0], ipma[3]
+	count := binary.BigEndian.Uint32(ipma[4:])
+	pos := 8
+
+	for range count {
+		var id uint32
+
+		if version < 1 {
+			if pos+2 > len(ipma) {
+				return nil, ErrInvalidBox
+			}
+
+			id = uint32(binary.BigEndian.Uint16(ipma[pos:]))
+			pos += 2
+		} else {
+			if pos+4 > len(ipma) {
+				return nil, ErrInvalidBox
+			}
+
+			id = binary.BigEndian.Uint32(ipma[pos:])
+			pos += 4
+		}
+
+		if pos >= len(ipma) {
+			return nil, ErrInvalidBox
+		}
+
+		associationCount := int(ipma[pos])
+		pos++
+
+		var indices []int
+
+		for range associationCount {
+			if flags&1 != 0 {
+				if pos+2 > len(ipma) {
+					return nil, ErrInvalidBox
+				}
+
+				indices = append(indices, int(binary.BigEndian.Uint16(ipma[pos:])&0x7fff))
+				pos += 2
+			} else {
+				if pos+1 > len(ipma) {
+					return nil, ErrInvalidBox
+				}
+
+				indices = append(indices, int(ipma[pos]&0x7f))
+				pos++
+			}
+		}
+
+		if id == item {
+			return indices, nil
+		}
+	}
+
+	return nil, ErrNoPrimaryItem
+}
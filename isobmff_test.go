@@ -0,0 +1,74 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func testBox(kind string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+
+	return append(binary.BigEndian.AppendUint32(nil, uint32(8+len(body))), append([]byte(kind), body...)...)
+}
+
+func testFullBox(kind string, payload ...[]byte) []byte {
+	return testBox(kind, append([][]byte{{0, 0, 0, 0}}, payload...)...)
+}
+
+// testHEIF assembles a HEIF file whose primary item has an ispe property and
+// the given transform properties.
+func testHEIF(width, height uint32, transforms ...[]byte) []byte {
+	ispe := testFullBox("ispe", binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(nil, width), height))
+
+	properties := append([][]byte{ispe}, transforms...)
+
+	associations := []byte{0, 1, byte(len(properties))}
+	for i := range properties {
+		associations = append(associations, 0x80|byte(i+1))
+	}
+
+	ipma := testFullBox("ipma", []byte{0, 0, 0, 1}, associations)
+	meta := testFullBox("meta",
+		testFullBox("hdlr", make([]byte, 4), []byte("pict"), make([]byte, 13)),
+		testFullBox("pitm", []byte{0, 1}),
+		testBox("iprp", testBox("ipco", properties...), ipma))
+
+	return append(append(testBox("ftyp", []byte("heic\x00\x00\x00\x00mif1heic")), meta...), testBox("mdat", make([]byte, 16))...)
+}
+
+func TestIsobmffDimensions(t *testing.T) {
+	tests := []struct {
+		name        string
+		transforms  [][]byte
+		width       int
+		height      int
+		orientation int
+	}{
+		{"no transform", nil, 4032, 3024, 0},
+		{"irot 90", [][]byte{testBox("irot", []byte{1})}, 3024, 4032, 1},
+		{"irot 180", [][]byte{testBox("irot", []byte{2})}, 4032, 3024, 1},
+		{"irot 270", [][]byte{testBox("irot", []byte{3})}, 3024, 4032, 1},
+		{"imir", [][]byte{testBox("imir", []byte{0})}, 4032, 3024, 1},
+		{"irot 90 and imir", [][]byte{testBox("irot", []byte{1}), testBox("imir", []byte{1})}, 3024, 4032, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var data imageData
+
+			if err := isobmffDimensions(bytes.NewReader(testHEIF(4032, 3024, test.transforms...)), &data); err != nil {
+				t.Fatal(err)
+			}
+
+			if data.width != test.width || data.height != test.height || data.orientation != test.orientation {
+				t.Errorf("got %dx%d with orientation %d, want %dx%d with orientation %d",
+					data.width, data.height, data.orientation, test.width, test.height, test.orientation)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidJPEG2000 = errors.New("invalid jpeg 2000 header")
+)
+
+const (
+	jp2Signature = "\x00\x00\x00\x0cjP  \r\n\x87\n"
+	j2kSignature = "\xff\x4f\xff\x51"
+
+	// maxJP2Header bounds the size of the jp2h box, which holds only the
+	// image header, colour specification and similar small boxes.
+	maxJP2Header = 1 << 20
+
+	// jp2VaryingDepth marks an ihdr whose components differ in bit depth,
+	// which are then listed in a bpcc box.
+	jp2VaryingDepth = 0xff
+)
+
+// sniffJP2 distinguishes JPX from plain JP2 files by the brand of the ftyp
+// box which follows the signature.
+func sniffJP2(header []byte) string {
+	ftyp := header[len(jp2Signature):]
+
+	if len(ftyp) >= 12 && string(ftyp[4:8]) == "ftyp" && string(ftyp[8:12]) == "jpx " {
+		return "jpx"
+	}
+
+	return "jp2"
+}
+
+// bitDepth converts a JPEG 2000 bit depth byte, which stores the depth less
+// one with the top bit marking signed samples.
+func bitDepth(value byte) int {
+	return int(value&0x7f) + 1
+}
+
+// jpeg2000Notes describes the component count and bit depths of an image
+// for verbose output.
+func jpeg2000Notes(depths []int) []string {
+	notes := []string{fmt.Sprintf("%d component(s)", len(depths))}
+
+	uniform := true
+
+	for _, depth := range depths[1:] {
+		if depth != depths[0] {
+			uniform = false
+		}
+	}
+
+	if uniform {
+		return append(notes, fmt.Sprintf("%d-bit", depths[0]))
+	}
+
+	values := make([]string, len(depths))
+
+	for i, depth := range depths {
+		values[i] = strconv.Itoa(depth)
+	}
+
+	return append(notes, strings.Join(values, "/")+"-bit")
+}
+
+// jp2Dimensions reads the ihdr box from the jp2h header box of a JP2 or JPX
+// file, along with the bpcc box if the components differ in bit depth.
+func jp2Dimensions(r io.ReadSeeker, data *imageData) error {
+	jp2h, err := findTopLevelBox(r, "jp2h", maxJP2Header)
+	if err != nil {
+		return err
+	}
+
+	boxes, err := children(jp2h)
+	if err != nil {
+		return err
+	}
+
+	var ihdr, bpcc []byte
+
+	for _, b := range boxes {
+		switch b.kind {
+		case "ihdr":
+			ihdr = b.data
+		case "bpcc":
+			bpcc = b.data
+		}
+	}
+
+	if len(ihdr) < 14 {
+		return ErrInvalidJPEG2000
+	}
+
+	height := binary.BigEndian.Uint32(ihdr)
+	width := binary.BigEndian.Uint32(ihdr[4:])
+	components := int(binary.BigEndian.Uint16(ihdr[8:]))
+
+	if width == 0 || height == 0 || components == 0 {
+		return ErrInvalidJPEG2000
+	}
+
+	depths := make([]int, components)
+
+	for i := range depths {
+		switch {
+		case ihdr[10] != jp2VaryingDepth:
+			depths[i] = bitDepth(ihdr[10])
+		case i < len(bpcc):
+			depths[i] = bitDepth(bpcc[i])
+		default:
+			return ErrInvalidJPEG2000
+		}
+	}
+
+	data.width, data.height = int(width), int(height)
+	data.notes = append(data.notes, jpeg2000Notes(depths)...)
+
+	return nil
+}
+
+// j2kDimensions reads the SIZ marker segment which immediately follows the
+// start of a raw JPEG 2000 codestream. The image area excludes the offset
+// of the image from the reference grid origin.
+func j2kDimensions(r io.ReadSeeker, data *imageData) error {
+	var header [42]byte
+
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return err
+	}
+
+	if string(header[:4]) != j2kSignature {
+		return ErrInvalidJPEG2000
+	}
+
+	xSize := binary.BigEndian.Uint32(header[8:])
+	ySize := binary.BigEndian.Uint32(header[12:])
+	xOffset := binary.BigEndian.Uint32(header[16:])
+	yOffset := binary.BigEndian.Uint32(header[20:])
+	components := int(binary.BigEndian.Uint16(header[40:]))
+
+	if xSize <= xOffset || ySize <= yOffset || components == 0 {
+		return ErrInvalidJPEG2000
+	}
+
+	// Each component is described by its depth and subsampling factors.
+	info := make([]byte, components*3)
+
+	if _, err := io.ReadFull(r, info); err != nil {
+		return err
+	}
+
+	depths := make([]int, components)
+
+	for i := range depths {
+		depths[i] = bitDepth(info[i*3])
+	}
+
+	data.width, data.height = int(xSize-xOffset), int(ySize-yOffset)
+	data.notes = append(data.notes, jpeg2000Notes(depths)...)
+
+	return nil
+}
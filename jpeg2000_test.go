@@ -0,0 +1,109 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"slices"
+	"testing"
+)
+
+// testJP2 assembles a JP2 file with the given brand, whose jp2h box holds an
+// ihdr box and any further boxes.
+func testJP2(brand string, width, height uint32, depths []byte, boxes ...[]byte) []byte {
+	ihdr := binary.BigEndian.AppendUint32(nil, height)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, width)
+	ihdr = binary.BigEndian.AppendUint16(ihdr, uint16(len(depths)))
+
+	depth := depths[0]
+	for _, d := range depths[1:] {
+		if d != depth {
+			depth = jp2VaryingDepth
+		}
+	}
+
+	ihdr = append(ihdr, depth, 7, 0, 0)
+
+	return bytes.Join([][]byte{
+		[]byte(jp2Signature),
+		testBox("ftyp", []byte(brand+"\x00\x00\x00\x00"+brand)),
+		testBox("jp2h", append([][]byte{testBox("ihdr", ihdr)}, boxes...)...),
+		testBox("jp2c", []byte(j2kSignature)),
+	}, nil)
+}
+
+// testJ2K assembles the start of a raw codestream with its SIZ segment.
+func testJ2K(width, height, xOffset, yOffset uint32, depths ...byte) []byte {
+	out := []byte(j2kSignature)
+	out = binary.BigEndian.AppendUint16(out, uint16(38+3*len(depths)))
+	out = binary.BigEndian.AppendUint16(out, 0)
+
+	for _, v := range []uint32{width + xOffset, height + yOffset, xOffset, yOffset, width, height, 0, 0} {
+		out = binary.BigEndian.AppendUint32(out, v)
+	}
+
+	out = binary.BigEndian.AppendUint16(out, uint16(len(depths)))
+
+	for _, depth := range depths {
+		out = append(out, depth, 1, 1)
+	}
+
+	return out
+}
+
+func TestJpeg2000Dimensions(t *testing.T) {
+	jp2 := testJP2("jp2 ", 1024, 768, []byte{7, 7, 7})
+
+	tests := []struct {
+		name    string
+		decoder func(io.ReadSeeker, *imageData) error
+		input   []byte
+		width   int
+		height  int
+		notes   []string
+		err     bool
+	}{
+		{"jp2", jp2Dimensions, jp2, 1024, 768, []string{"3 component(s)", "8-bit"}, false},
+		{"jpx with bpcc", jp2Dimensions, testJP2("jpx ", 640, 480, []byte{7, 4}, testBox("bpcc", []byte{7, 4})), 640, 480, []string{"2 component(s)", "8/5-bit"}, false},
+		{"missing bpcc", jp2Dimensions, testJP2("jp2 ", 640, 480, []byte{7, 4}), 0, 0, nil, true},
+		{"truncated jp2", jp2Dimensions, jp2[:40], 0, 0, nil, true},
+		{"j2k", j2kDimensions, testJ2K(2048, 1080, 0, 0, 11, 11, 11), 2048, 1080, []string{"3 component(s)", "12-bit"}, false},
+		{"j2k with offset", j2kDimensions, testJ2K(100, 50, 16, 8, 0x87), 100, 50, []string{"1 component(s)", "8-bit"}, false},
+		{"truncated j2k", j2kDimensions, testJ2K(2048, 1080, 0, 0, 11, 11, 11)[:44], 0, 0, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var data imageData
+
+			err := test.decoder(bytes.NewReader(test.input), &data)
+			if test.err {
+				if err == nil {
+					t.Errorf("got %dx%d, want error", data.width, data.height)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if data.width != test.width || data.height != test.height || !slices.Equal(data.notes, test.notes) {
+				t.Errorf("got %dx%d %q, want %dx%d %q", data.width, data.height, data.notes, test.width, test.height, test.notes)
+			}
+		})
+	}
+}
+
+func TestSniffJP2(t *testing.T) {
+	for brand, want := range map[string]string{"jp2 ": "jp2", "jpx ": "jpx"} {
+		if got := sniff("", testJP2(brand, 1, 1, []byte{7})); got != want {
+			t.Errorf("brand %q: got %q, want %q", brand, got, want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+var (
+	ErrInvalidJXL = errors.New("invalid jpeg xl header")
+)
+
+// jxlHeaderLength is enough of the codestream to hold the signature, the
+// SizeHeader and the start of the ImageMetadata.
+const jxlHeaderLength = 64
+
+var jxlRatios = [8][2]uint64{
+	{0, 0}, {1, 1}, {12, 10}, {4, 3}, {3, 2}, {16, 9}, {5, 4}, {2, 1},
+}
+
+// bitReader reads values packed least-significant bit first, as used by the
+// JPEG XL codestream.
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+func (b *bitReader) read(n int) (uint64, error) {
+	var value uint64
+
+	for i := range n {
+		if b.pos/8 >= len(b.data) {
+			return 0, ErrInvalidJXL
+		}
+
+		bit := b.data[b.pos/8] >> (b.pos % 8) & 1
+		value |= uint64(bit) << i
+		b.pos++
+	}
+
+	return value, nil
+}
+
+// readU32 reads a U32 field whose two-bit selector picks one of the given
+// bit widths.
+func (b *bitReader) readU32(widths [4]int) (uint64, error) {
+	selector, err := b.read(2)
+	if err != nil {
+		return 0, err
+	}
+
+	return b.read(widths[selector])
+}
+
+// jxlDimensions reads the dimensions of a JPEG XL image from the SizeHeader
+// at the start of its codestream, either bare or wrapped in a container.
+func jxlDimensions(r io.ReadSeeker, data *imageData) error {
+	codestream, err := jxlCodestream(r)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.HasPrefix(codestream, []byte("\xff\x0a")) {
+		return ErrInvalidJXL
+	}
+
+	b := &bitReader{data: codestream[2:]}
+
+	small, err := b.read(1)
+	if err != nil {
+		return err
+	}
+
+	sizeWidths := [4]int{9, 13, 18, 30}
+
+	readSize := func() (uint64, error) {
+		if small == 1 {
+			v, err := b.read(5)
+
+			return (v + 1) * 8, err
+		}
+
+		v, err := b.readU32(sizeWidths)
+
+		return v + 1, err
+	}
+
+	height, err := readSize()
+	if err != nil {
+		return err
+	}
+
+	ratio, err := b.read(3)
+	if err != nil {
+		return err
+	}
+
+	var width uint64
+
+	if ratio == 0 {
+		width, err = readSize()
+		if err != nil {
+			return err
+		}
+	} else {
+		width = height * jxlRatios[ratio][0] / jxlRatios[ratio][1]
+	}
+
+	data.width, data.height = int(width), int(height)
+	data.orientation = jxlOrientation(b)
+
+	return nil
+}
+
+// jxlOrientation reads the orientation from the ImageMetadata that follows
+// the SizeHeader, defaulting to 1 if it is absent or cannot be read.
+func jxlOrientation(b *bitReader) int {
+	allDefault, err := b.read(1)
+	if err != nil || allDefault == 1 {
+		return 1
+	}
+
+	extraFields, err := b.read(1)
+	if err != nil || extraFields == 0 {
+		return 1
+	}
+
+	value, err := b.read(3)
+	if err != nil {
+		return 1
+	}
+
+	return int(value) + 1
+}
+
+// jxlCodestream returns the first bytes of the codestream, taken either from
+// the start of the file or from the first jxlc or jxlp box of a container.
+func jxlCodestream(r io.ReadSeeker) ([]byte, error) {
+	header, err := readHeader(io.LimitReader(r, jxlHeaderLength))
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(header, []byte("\xff\x0a")) {
+		return header, nil
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	for {
+		kind, size, err := readBoxHeader(r)
+		if err != nil {
+			return nil, err
+		}
+
+		switch kind {
+		case "jxlc":
+			return readHeader(io.LimitReader(r, jxlHeaderLength))
+		case "jxlp":
+			// Partial codestream boxes begin with a sequence number.
+			if _, err := io.CopyN(io.Discard, r, 4); err != nil {
+				return nil, err
+			}
+
+			return readHeader(io.LimitReader(r, jxlHeaderLength))
+		}
+
+		if size == -1 {
+			return nil, ErrInvalidJXL
+		}
+
+		if _, err := r.Seek(size, io.SeekCurrent); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+// testJXL packs the given (value, width) pairs least-significant bit first
+// after a bare codestream signature.
+func testJXL(fields ...[2]uint64) []byte {
+	out := []byte("\xff\x0a")
+
+	var pos int
+
+	for _, field := range fields {
+		for i := range field[1] {
+			if pos%8 == 0 {
+				out = append(out, 0)
+			}
+
+			out[len(out)-1] |= byte(field[0]>>i&1) << (pos % 8)
+			pos++
+		}
+	}
+
+	return out
+}
+
+func TestJxlDimensions(t *testing.T) {
+	// small, height, ratio, width
+	small := testJXL([2]uint64{1, 1}, [2]uint64{3, 5}, [2]uint64{0, 3}, [2]uint64{7, 5})
+
+	// not small, selector 1 with a 13-bit height, 16:9 ratio, then
+	// ImageMetadata with extra fields and orientation 6
+	large := testJXL([2]uint64{0, 1}, [2]uint64{1, 2}, [2]uint64{999, 13}, [2]uint64{5, 3},
+		[2]uint64{0, 1}, [2]uint64{1, 1}, [2]uint64{5, 3})
+
+	container := bytes.Join([][]byte{
+		[]byte("\x00\x00\x00\x0cJXL \r\n\x87\n"),
+		testBox("ftyp", []byte("jxl \x00\x00\x00\x00jxl ")),
+		testBox("jxlc", small),
+	}, nil)
+
+	tests := []struct {
+		name        string
+		input       []byte
+		width       int
+		height      int
+		orientation int
+		err         bool
+	}{
+		{"small", small, 64, 32, 1, false},
+		{"ratio and orientation", large, 1777, 1000, 6, false},
+		{"container", container, 64, 32, 1, false},
+		{"truncated", small[:3], 0, 0, 0, true},
+		{"signature only", []byte("\xff\x0a"), 0, 0, 0, true},
+		{"bad signature", []byte("\xff\xd8\xff\xe0"), 0, 0, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var data imageData
+
+			err := jxlDimensions(bytes.NewReader(test.input), &data)
+			if test.err {
+				if err == nil {
+					t.Errorf("got %dx%d, want error", data.width, data.height)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if data.width != test.width || data.height != test.height || data.orientation != test.orientation {
+				t.Errorf("got %dx%d with orientation %d, want %dx%d with orientation %d",
+					data.width, data.height, data.orientation, test.width, test.height, test.orientation)
+			}
+		})
+	}
+}
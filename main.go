@@ -5,39 +5,107 @@ Copyright © 2026 Seednode <seednode@seedno.de>
 package main
 
 import (
+	"errors"
 	"log"
+	"os"
 
 	"github.com/spf13/cobra"
 )
 
 const (
-	ReleaseVersion string = "1.2.0"
+	ReleaseVersion string = "1.27.0"
 )
 
 var (
-	concurrency int
-	orEqual     bool
-	recursive   bool
-	key         string
-	order       string
-	verbose     bool
-	version     bool
+	archives      bool
+	documents     bool
+	excludes      []string
+	excludeDirs   []string
+	exclusiveMax  bool
+	exclusiveMin  bool
+	exifOrient    bool
+	filesFrom     string
+	follow        bool
+	formats       []string
+	iconEntry     string
+	includes      []string
+	concurrency   int
+	maxDepth      int
+	minDepth      int
+	noIgnore      bool
+	notFormats    []string
+	orEqual       bool
+	allPages      bool
+	nonPowerOfTwo bool
+	rawSize       string
+	recursive     bool
+	key           string
+	order         string
+	tolerance     float64
+	verbose       bool
+	version       bool
+	where         string
 )
 
 var rootCmd = &cobra.Command{
-	Use:              "imagesize",
+	Use:              "imagesize [path1] ...[pathN]",
 	Short:            "displays images matching the specified constraints",
+	Args:             cobra.ArbitraryArgs,
 	TraverseChildren: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if where == "" && len(formats) == 0 && len(notFormats) == 0 && !nonPowerOfTwo {
+			return cmd.Help()
+		}
+
+		err := imageSizes(cmd, nil, args)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+// exitCode returns the exit status for the result of a run, following grep:
+// 0 if it succeeded, 1 if an image read from stdin did not match, and 2 for
+// any other error.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, ErrNoMatch):
+		return 1
+	default:
+		return 2
+	}
 }
 
 func main() {
+	rootCmd.PersistentFlags().BoolVar(&archives, "archives", false, "also scan images inside zip, cbz, rar, cbr, tar, tar.gz and tar.zst archives")
+	rootCmd.PersistentFlags().BoolVar(&documents, "documents", false, "also scan images embedded in pdf, epub, docx, xlsx, pptx and opendocument files")
+	rootCmd.PersistentFlags().StringArrayVar(&excludes, "exclude", nil, "skip files and directories matching the specified glob (e.g. 'node_modules/**') (repeatable)")
+	rootCmd.PersistentFlags().StringArrayVar(&excludeDirs, "exclude-dir", nil, "skip directories matching the specified glob (repeatable)")
+	rootCmd.PersistentFlags().BoolVar(&exifOrient, "exif-orient", false, "use displayed dimensions, accounting for EXIF orientation")
+	rootCmd.PersistentFlags().StringVar(&filesFrom, "files-from", "", "also scan the newline- or NUL-delimited paths listed in the specified file (- for stdin)")
+	rootCmd.PersistentFlags().BoolVarP(&follow, "follow", "L", false, "follow symlinks to directories, skipping loops")
+	rootCmd.PersistentFlags().StringSliceVarP(&formats, "format", "f", nil, "only match images in the specified formats (e.g. png,webp,avif)")
+	rootCmd.PersistentFlags().StringVar(&iconEntry, "icon-entry", "largest", "which entries of ICO/CUR files to match (largest, smallest, all)")
+	rootCmd.PersistentFlags().StringArrayVar(&includes, "include", nil, "only scan files matching the specified glob (e.g. '*.png') (repeatable)")
 	rootCmd.PersistentFlags().IntVarP(&concurrency, "max-concurrency", "c", 4096, "maximum number of paths to scan at once")
+	rootCmd.PersistentFlags().IntVar(&maxDepth, "max-depth", -1, "descend at most the specified number of directory levels, as with find (implies --recursive)")
+	rootCmd.PersistentFlags().IntVar(&minDepth, "min-depth", 0, "skip files fewer than the specified number of levels deep, as with find (implies --recursive)")
+	rootCmd.PersistentFlags().BoolVar(&noIgnore, "no-ignore", false, "do not honour .gitignore, .ignore and .imagesizeignore files when scanning recursively")
+	rootCmd.PersistentFlags().StringSliceVarP(&notFormats, "not-format", "F", nil, "do not match images in the specified formats")
 	rootCmd.PersistentFlags().BoolVarP(&orEqual, "or-equal", "e", false, "also match files equal to the specified dimension")
+	rootCmd.PersistentFlags().BoolVar(&allPages, "pages", false, "report each page of multi-page images separately (e.g. scan.tif#2)")
+	rootCmd.PersistentFlags().BoolVar(&nonPowerOfTwo, "pow2", false, "only match images with a width or height that is not a power of two")
+	rootCmd.PersistentFlags().StringVar(&rawSize, "raw-dimensions", "sensor", "which dimensions of camera RAW files to match (sensor, crop)")
 	rootCmd.PersistentFlags().BoolVarP(&recursive, "recursive", "r", false, "include subdirectories")
-	rootCmd.PersistentFlags().StringVarP(&key, "sort-key", "k", "name", "sort output by the specified key (height, width, name)")
+	rootCmd.PersistentFlags().StringVarP(&key, "sort-key", "k", "name", "sort output by the specified key (height, width, name, ratio, area, format)")
 	rootCmd.PersistentFlags().StringVarP(&order, "sort-order", "o", "ascending", "sort output in the specified direction (asc[ending], desc[ending])")
-	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "display image dimensions and total matched file count")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "display image dimensions, format, and total matched file count")
 	rootCmd.PersistentFlags().BoolVarP(&version, "version", "V", false, "display version and exit")
+	rootCmd.PersistentFlags().StringVarP(&where, "where", "w", "", "only match images satisfying the specified expression (e.g. 'width > 1920 && ratio == 16:9')")
 
 	rootCmd.Flags().SetInterspersed(true)
 
@@ -52,7 +120,9 @@ func main() {
 	rootCmd.Version = ReleaseVersion
 
 	err := rootCmd.Execute()
-	if err != nil {
-		log.Fatal(err)
+	if err != nil && !errors.Is(err, ErrNoMatch) {
+		log.Print(err)
 	}
+
+	os.Exit(exitCode(err))
 }
@@ -0,0 +1,152 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidNetpbm = errors.New("invalid netpbm header")
+)
+
+// maxNetpbmHeader bounds how much of a Netpbm file is read looking for its
+// dimensions, which may be preceded by arbitrarily many comments.
+const maxNetpbmHeader = 64 << 10
+
+var netpbmFormats = map[string]string{
+	"P1": "pbm",
+	"P2": "pgm",
+	"P3": "ppm",
+	"P4": "pbm",
+	"P5": "pgm",
+	"P6": "ppm",
+	"P7": "pam",
+	"PF": "pfm",
+	"Pf": "pfm",
+}
+
+// sniffNetpbm returns the format of a Netpbm file from its magic number,
+// which must be followed by whitespace.
+func sniffNetpbm(header []byte) string {
+	if len(header) < 3 || !isSpace(header[2]) {
+		return ""
+	}
+
+	return netpbmFormats[string(header[:2])]
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\v' || c == '\f'
+}
+
+// netpbmTokens splits the header of a PBM, PGM, PPM or PFM file into its
+// whitespace-separated fields, skipping comments.
+func netpbmTokens(br *bufio.Reader, count int) ([]string, error) {
+	var tokens []string
+	var current []byte
+
+	for len(tokens) < count {
+		c, err := br.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case c == '#':
+			if _, err := br.ReadString('\n'); err != nil {
+				return nil, err
+			}
+		case isSpace(c):
+			if len(current) > 0 {
+				tokens = append(tokens, string(current))
+				current = current[:0]
+			}
+		default:
+			current = append(current, c)
+		}
+	}
+
+	return tokens, nil
+}
+
+// netpbmDimensions reads the dimensions of a PBM, PGM, PPM, PAM or PFM file.
+func netpbmDimensions(r io.ReadSeeker, data *imageData) error {
+	br := bufio.NewReader(io.LimitReader(r, maxNetpbmHeader))
+
+	var width, height int
+	var err error
+
+	magic := make([]byte, 2)
+
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return err
+	}
+
+	if string(magic) == "P7" {
+		width, height, err = pamDimensions(br)
+	} else {
+		var tokens []string
+
+		tokens, err = netpbmTokens(br, 2)
+		if err != nil {
+			return err
+		}
+
+		width, err = strconv.Atoi(tokens[0])
+		if err == nil {
+			height, err = strconv.Atoi(tokens[1])
+		}
+	}
+
+	if err != nil || width <= 0 || height <= 0 {
+		return ErrInvalidNetpbm
+	}
+
+	data.width, data.height = width, height
+
+	return nil
+}
+
+// pamDimensions reads the WIDTH and HEIGHT lines of a PAM header.
+func pamDimensions(br *bufio.Reader) (int, int, error) {
+	var width, height int
+
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return 0, 0, err
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		switch fields[0] {
+		case "ENDHDR":
+			return width, height, nil
+		case "WIDTH", "HEIGHT":
+			if len(fields) < 2 {
+				return 0, 0, ErrInvalidNetpbm
+			}
+
+			value, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return 0, 0, ErrInvalidNetpbm
+			}
+
+			if fields[0] == "WIDTH" {
+				width = value
+			} else {
+				height = value
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNetpbmDimensions(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		width  int
+		height int
+		err    bool
+	}{
+		{"pbm", "P4\n640 480\n", 640, 480, false},
+		{"ppm with comments", "P6\n# created by a scanner\n# at 300 dpi\n800\t600 255\n", 800, 600, false},
+		{"pgm on one line", "P5 32 16 255 ", 32, 16, false},
+		{"pfm", "PF\n100 50\n-1.0\n", 100, 50, false},
+		{"pam", "P7\nWIDTH 227\nHEIGHT 149\nDEPTH 3\nMAXVAL 255\nTUPLTYPE RGB\nENDHDR\n", 227, 149, false},
+		{"zero width", "P6\n0 480\n", 0, 0, true},
+		{"not a number", "P6\nwide 480\n", 0, 0, true},
+		{"truncated", "P6\n640", 0, 0, true},
+		{"truncated pam", "P7\nWIDTH 227\n", 0, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var data imageData
+
+			err := netpbmDimensions(strings.NewReader(test.input), &data)
+			if test.err {
+				if err == nil {
+					t.Errorf("got %dx%d, want error", data.width, data.height)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if data.width != test.width || data.height != test.height {
+				t.Errorf("got %dx%d, want %dx%d", data.width, data.height, test.width, test.height)
+			}
+		})
+	}
+}
+
+func TestSniffNetpbm(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"P1\n", "pbm"},
+		{"P5 ", "pgm"},
+		{"P6\r\n", "ppm"},
+		{"P7\n", "pam"},
+		{"Pf\n", "pfm"},
+		{"P6", ""},
+		{"P8\n", ""},
+		{"PK\x03\x04", ""},
+	}
+
+	for _, test := range tests {
+		if got := sniffNetpbm([]byte(test.header)); got != test.want {
+			t.Errorf("sniffNetpbm(%q) = %q, want %q", test.header, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var orientationCmd = &cobra.Command{
+	Use:       "orientation <portrait|landscape|square> [path1] ...[pathN]",
+	Short:     "Filter images by orientation",
+	Args:      cobra.MinimumNArgs(1),
+	ValidArgs: []string{"portrait", "landscape", "square"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, err := orientationFilter(args[0])
+		if err != nil {
+			return err
+		}
+
+		err = imageSizes(cmd, filter, args[1:])
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(orientationCmd)
+}
@@ -0,0 +1,61 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+var (
+	ErrInvalidPCX = errors.New("invalid pcx header")
+)
+
+const pcxHeaderLength = 128
+
+// isPCX reports whether header looks like a ZSoft PCX header. The signature
+// is a single byte, so the version, encoding and bit depth are checked too.
+func isPCX(header []byte) bool {
+	if len(header) < pcxHeaderLength || header[0] != 0x0a || header[64] != 0 {
+		return false
+	}
+
+	switch header[1] {
+	case 0, 2, 3, 4, 5:
+	default:
+		return false
+	}
+
+	switch header[3] {
+	case 1, 2, 4, 8:
+	default:
+		return false
+	}
+
+	return header[2] == 1
+}
+
+// pcxDimensions reads the image window from a PCX header.
+func pcxDimensions(r io.ReadSeeker, data *imageData) error {
+	header := make([]byte, pcxHeaderLength)
+
+	if _, err := io.ReadFull(r, header); err != nil {
+		return err
+	}
+
+	xMin := int(binary.LittleEndian.Uint16(header[4:]))
+	yMin := int(binary.LittleEndian.Uint16(header[6:]))
+	xMax := int(binary.LittleEndian.Uint16(header[8:]))
+	yMax := int(binary.LittleEndian.Uint16(header[10:]))
+
+	if xMax < xMin || yMax < yMin {
+		return ErrInvalidPCX
+	}
+
+	data.width, data.height = xMax-xMin+1, yMax-yMin+1
+
+	return nil
+}
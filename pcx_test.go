@@ -0,0 +1,88 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func testPCX(xMin, yMin, xMax, yMax uint16) []byte {
+	header := make([]byte, pcxHeaderLength)
+	header[0], header[1], header[2], header[3] = 0x0a, 5, 1, 8
+
+	for i, v := range []uint16{xMin, yMin, xMax, yMax} {
+		binary.LittleEndian.PutUint16(header[4+i*2:], v)
+	}
+
+	return header
+}
+
+func TestPcxDimensions(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		width  int
+		height int
+		err    bool
+	}{
+		{"origin", testPCX(0, 0, 639, 479), 640, 480, false},
+		{"offset window", testPCX(10, 20, 109, 69), 100, 50, false},
+		{"inverted window", testPCX(100, 0, 10, 479), 0, 0, true},
+		{"truncated", testPCX(0, 0, 639, 479)[:64], 0, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var data imageData
+
+			err := pcxDimensions(bytes.NewReader(test.input), &data)
+			if test.err {
+				if err == nil {
+					t.Errorf("got %dx%d, want error", data.width, data.height)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if data.width != test.width || data.height != test.height {
+				t.Errorf("got %dx%d, want %dx%d", data.width, data.height, test.width, test.height)
+			}
+		})
+	}
+}
+
+func TestIsPCX(t *testing.T) {
+	bad := func(offset int, value byte) []byte {
+		header := testPCX(0, 0, 639, 479)
+		header[offset] = value
+
+		return header
+	}
+
+	tests := []struct {
+		name   string
+		header []byte
+		want   bool
+	}{
+		{"valid", testPCX(0, 0, 639, 479), true},
+		{"bad version", bad(1, 1), false},
+		{"bad encoding", bad(2, 0), false},
+		{"bad depth", bad(3, 3), false},
+		{"reserved byte set", bad(64, 1), false},
+		{"truncated", testPCX(0, 0, 639, 479)[:100], false},
+	}
+
+	for _, test := range tests {
+		if got := isPCX(test.header); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,1179 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+var (
+	ErrInvalidPDF = errors.New("invalid pdf structure")
+)
+
+const (
+	pdfSignature = "%PDF-"
+
+	// maxPDFObjects bounds the number of objects listed by the cross-reference
+	// sections of a single file.
+	maxPDFObjects = 1 << 22
+
+	// maxPDFStream bounds the size of a decoded cross-reference or object
+	// stream, which hold only object metadata.
+	maxPDFStream = 64 << 20
+
+	// maxPDFDepth bounds the nesting of page tree nodes and of form XObjects
+	// drawn within one another.
+	maxPDFDepth = 32
+
+	// pdfTailLength is how much of the end of a file is searched for the
+	// offset of the last cross-reference section.
+	pdfTailLength = 1024
+)
+
+type (
+	pdfName   string
+	pdfString string
+	pdfArray  []any
+	pdfDict   map[pdfName]any
+)
+
+type pdfRef struct {
+	num, gen int
+}
+
+// A pdfStream is the dictionary of a stream object, along with the offset
+// at which its data begins.
+type pdfStream struct {
+	dict   pdfDict
+	offset int64
+}
+
+// A pdfXref locates an object, either at an offset in the file or at an
+// index within an object stream.
+type pdfXref struct {
+	offset int64
+	stream int
+	index  int
+}
+
+// pdfReader reads objects from a PDF file on demand via its cross-reference
+// table, so that image data is never read.
+type pdfReader struct {
+	r       io.ReaderAt
+	size    int64
+	xref    map[int]pdfXref
+	trailer pdfDict
+	objects map[int]any
+	streams map[int][]byte
+}
+
+var pdfRefPattern = regexp.MustCompile(`^\s+(\d+)\s+R`)
+
+// pdfLexer parses PDF values from a stream of bytes, tracking its position
+// so that the data of stream objects can be located.
+type pdfLexer struct {
+	br  *bufio.Reader
+	pos int64
+}
+
+func newPDFLexer(r io.Reader, pos int64) *pdfLexer {
+	return &pdfLexer{br: bufio.NewReader(r), pos: pos}
+}
+
+func (l *pdfLexer) readByte() (byte, error) {
+	c, err := l.br.ReadByte()
+	if err == nil {
+		l.pos++
+	}
+
+	return c, err
+}
+
+func (l *pdfLexer) unreadByte() {
+	if l.br.UnreadByte() == nil {
+		l.pos--
+	}
+}
+
+func (l *pdfLexer) peek() (byte, error) {
+	c, err := l.br.Peek(1)
+	if err != nil {
+		return 0, err
+	}
+
+	return c[0], nil
+}
+
+func isPDFSpace(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isPDFDelimiter(c byte) bool {
+	return bytes.IndexByte([]byte("()<>[]{}/%"), c) != -1
+}
+
+// skipSpace skips whitespace and comments.
+func (l *pdfLexer) skipSpace() error {
+	for {
+		c, err := l.readByte()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case c == '%':
+			for c != '\n' && c != '\r' {
+				if c, err = l.readByte(); err != nil {
+					return err
+				}
+			}
+		case !isPDFSpace(c):
+			l.unreadByte()
+
+			return nil
+		}
+	}
+}
+
+// regular reads a run of regular characters, such as a keyword or number.
+func (l *pdfLexer) regular() (string, error) {
+	var b []byte
+
+	for {
+		c, err := l.readByte()
+		if errors.Is(err, io.EOF) && len(b) > 0 {
+			return string(b), nil
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		if isPDFSpace(c) || isPDFDelimiter(c) {
+			l.unreadByte()
+
+			return string(b), nil
+		}
+
+		b = append(b, c)
+	}
+}
+
+// value parses the next value, resolving "num gen R" sequences into
+// references.
+func (l *pdfLexer) value() (any, error) {
+	if err := l.skipSpace(); err != nil {
+		return nil, err
+	}
+
+	c, err := l.readByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch c {
+	case '/':
+		word, err := l.regular()
+		if err != nil {
+			return nil, err
+		}
+
+		return pdfName(unescapeName(word)), nil
+	case '(':
+		return l.literalString()
+	case '<':
+		next, err := l.peek()
+		if err != nil {
+			return nil, err
+		}
+
+		if next == '<' {
+			l.readByte()
+
+			return l.dict()
+		}
+
+		return l.hexString()
+	case '[':
+		return l.array()
+	case ']', '>', ')', '{', '}':
+		return nil, ErrInvalidPDF
+	}
+
+	l.unreadByte()
+
+	word, err := l.regular()
+	if err != nil {
+		return nil, err
+	}
+
+	switch word {
+	case "":
+		return nil, ErrInvalidPDF
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+
+	if n, err := strconv.ParseInt(word, 10, 64); err == nil {
+		// An integer followed by another and R is an indirect reference.
+		ahead, _ := l.br.Peek(32)
+
+		if m := pdfRefPattern.FindSubmatch(ahead); m != nil && (len(m[0]) == len(ahead) || isPDFSpace(ahead[len(m[0])]) || isPDFDelimiter(ahead[len(m[0])])) {
+			gen, _ := strconv.Atoi(string(m[1]))
+
+			l.br.Discard(len(m[0]))
+			l.pos += int64(len(m[0]))
+
+			return pdfRef{num: int(n), gen: gen}, nil
+		}
+
+		return n, nil
+	}
+
+	if f, err := strconv.ParseFloat(word, 64); err == nil {
+		return f, nil
+	}
+
+	// Any other keyword, such as stream or endobj, is returned as is for the
+	// caller to interpret.
+	return word, nil
+}
+
+func unescapeName(name string) string {
+	if !bytes.ContainsRune([]byte(name), '#') {
+		return name
+	}
+
+	var b []byte
+
+	for i := 0; i < len(name); i++ {
+		if name[i] == '#' && i+2 < len(name) {
+			if v, err := strconv.ParseUint(name[i+1:i+3], 16, 8); err == nil {
+				b = append(b, byte(v))
+				i += 2
+
+				continue
+			}
+		}
+
+		b = append(b, name[i])
+	}
+
+	return string(b)
+}
+
+func (l *pdfLexer) literalString() (pdfString, error) {
+	var b []byte
+
+	depth := 1
+
+	for {
+		c, err := l.readByte()
+		if err != nil {
+			return "", err
+		}
+
+		switch c {
+		case '\\':
+			if c, err = l.readByte(); err != nil {
+				return "", err
+			}
+		case '(':
+			depth++
+		case ')':
+			depth--
+
+			if depth == 0 {
+				return pdfString(b), nil
+			}
+		}
+
+		b = append(b, c)
+	}
+}
+
+func (l *pdfLexer) hexString() (pdfString, error) {
+	var b []byte
+
+	for {
+		c, err := l.readByte()
+		if err != nil {
+			return "", err
+		}
+
+		if c == '>' {
+			return pdfString(b), nil
+		}
+
+		b = append(b, c)
+	}
+}
+
+func (l *pdfLexer) array() (pdfArray, error) {
+	var a pdfArray
+
+	for {
+		if err := l.skipSpace(); err != nil {
+			return nil, err
+		}
+
+		c, err := l.peek()
+		if err != nil {
+			return nil, err
+		}
+
+		if c == ']' {
+			l.readByte()
+
+			return a, nil
+		}
+
+		v, err := l.value()
+		if err != nil {
+			return nil, err
+		}
+
+		a = append(a, v)
+	}
+}
+
+func (l *pdfLexer) dict() (pdfDict, error) {
+	d := make(pdfDict)
+
+	for {
+		if err := l.skipSpace(); err != nil {
+			return nil, err
+		}
+
+		c, err := l.peek()
+		if err != nil {
+			return nil, err
+		}
+
+		if c == '>' {
+			l.readByte()
+
+			if c, err = l.readByte(); err != nil || c != '>' {
+				return nil, ErrInvalidPDF
+			}
+
+			return d, nil
+		}
+
+		key, err := l.value()
+		if err != nil {
+			return nil, err
+		}
+
+		name, ok := key.(pdfName)
+		if !ok {
+			return nil, ErrInvalidPDF
+		}
+
+		v, err := l.value()
+		if err != nil {
+			return nil, err
+		}
+
+		d[name] = v
+	}
+}
+
+// object parses the value of an indirect object, "num gen obj value", from
+// the current position. Streams are returned as a pdfStream.
+func (l *pdfLexer) object() (any, error) {
+	for range 2 {
+		if _, err := l.value(); err != nil {
+			return nil, err
+		}
+	}
+
+	if keyword, err := l.value(); err != nil || keyword != "obj" {
+		return nil, ErrInvalidPDF
+	}
+
+	v, err := l.value()
+	if err != nil {
+		return nil, err
+	}
+
+	d, ok := v.(pdfDict)
+	if !ok {
+		return v, nil
+	}
+
+	if keyword, err := l.value(); err != nil || keyword != "stream" {
+		return d, nil
+	}
+
+	// The stream keyword is followed by CRLF or LF before the data.
+	c, err := l.readByte()
+	if err != nil {
+		return nil, err
+	}
+
+	if c == '\r' {
+		if c, err = l.readByte(); err != nil {
+			return nil, err
+		}
+	}
+
+	if c != '\n' {
+		l.unreadByte()
+	}
+
+	return pdfStream{dict: d, offset: l.pos}, nil
+}
+
+// isPDF reports whether header looks like the start of a PDF file. Some
+// writers place junk before the signature, which readers tolerate.
+func isPDF(header []byte) bool {
+	return bytes.Contains(header, []byte(pdfSignature))
+}
+
+func newPDFReader(r io.ReaderAt, size int64) (*pdfReader, error) {
+	p := &pdfReader{
+		r:       r,
+		size:    size,
+		xref:    make(map[int]pdfXref),
+		objects: make(map[int]any),
+		streams: make(map[int][]byte),
+	}
+
+	tailLength := min(size, pdfTailLength)
+	tail := make([]byte, tailLength)
+
+	if _, err := r.ReadAt(tail, size-tailLength); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	i := bytes.LastIndex(tail, []byte("startxref"))
+	if i == -1 {
+		return nil, ErrInvalidPDF
+	}
+
+	fields := bytes.Fields(tail[i+len("startxref"):])
+	if len(fields) == 0 {
+		return nil, ErrInvalidPDF
+	}
+
+	offset, err := strconv.ParseInt(string(fields[0]), 10, 64)
+	if err != nil {
+		return nil, ErrInvalidPDF
+	}
+
+	seen := make(map[int64]bool)
+
+	// Later sections take precedence, so each earlier section only adds the
+	// objects not already listed.
+	for offset > 0 && !seen[offset] {
+		seen[offset] = true
+
+		trailer, err := p.readXref(offset)
+		if err != nil {
+			if p.trailer == nil {
+				return nil, err
+			}
+
+			break
+		}
+
+		if p.trailer == nil {
+			p.trailer = trailer
+		}
+
+		// Hybrid files list objects in streams in a separate section.
+		if stm, ok := trailer["XRefStm"].(int64); ok && !seen[stm] {
+			seen[stm] = true
+
+			p.readXref(stm)
+		}
+
+		prev, _ := trailer["Prev"].(int64)
+		offset = prev
+	}
+
+	if p.trailer == nil {
+		return nil, ErrInvalidPDF
+	}
+
+	return p, nil
+}
+
+func (p *pdfReader) lexer(offset int64) *pdfLexer {
+	return newPDFLexer(io.NewSectionReader(p.r, offset, p.size-offset), offset)
+}
+
+// readXref reads the cross-reference table or stream at offset, returning
+// its trailer dictionary.
+func (p *pdfReader) readXref(offset int64) (pdfDict, error) {
+	if offset < 0 || offset >= p.size {
+		return nil, ErrInvalidPDF
+	}
+
+	l := p.lexer(offset)
+
+	if err := l.skipSpace(); err != nil {
+		return nil, err
+	}
+
+	ahead, _ := l.br.Peek(4)
+	if string(ahead) != "xref" {
+		return p.readXrefStream(l)
+	}
+
+	l.regular()
+
+	for {
+		v, err := l.value()
+		if err != nil {
+			return nil, err
+		}
+
+		if v == "trailer" {
+			break
+		}
+
+		start, ok := v.(int64)
+		if !ok {
+			return nil, ErrInvalidPDF
+		}
+
+		v, err = l.value()
+		if err != nil {
+			return nil, err
+		}
+
+		count, ok := v.(int64)
+		if !ok || start < 0 || count < 0 || start+count > maxPDFObjects {
+			return nil, ErrInvalidPDF
+		}
+
+		for i := range count {
+			var fields [3]any
+
+			for j := range fields {
+				if fields[j], err = l.value(); err != nil {
+					return nil, err
+				}
+			}
+
+			entryOffset, ok := fields[0].(int64)
+			if !ok {
+				return nil, ErrInvalidPDF
+			}
+
+			num := int(start + i)
+
+			if _, exists := p.xref[num]; !exists && fields[2] == "n" {
+				p.xref[num] = pdfXref{offset: entryOffset}
+			}
+		}
+	}
+
+	v, err := l.value()
+	if err != nil {
+		return nil, err
+	}
+
+	trailer, ok := v.(pdfDict)
+	if !ok {
+		return nil, ErrInvalidPDF
+	}
+
+	return trailer, nil
+}
+
+// readXrefStream reads a cross-reference stream, whose dictionary doubles as
+// the trailer.
+func (p *pdfReader) readXrefStream(l *pdfLexer) (pdfDict, error) {
+	v, err := l.object()
+	if err != nil {
+		return nil, err
+	}
+
+	stream, ok := v.(pdfStream)
+	if !ok || stream.dict["Type"] != pdfName("XRef") {
+		return nil, ErrInvalidPDF
+	}
+
+	data, err := p.streamData(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	widths, ok := stream.dict["W"].(pdfArray)
+	if !ok || len(widths) != 3 {
+		return nil, ErrInvalidPDF
+	}
+
+	var w [3]int
+
+	for i := range w {
+		width, ok := widths[i].(int64)
+		if !ok || width < 0 || width > 8 {
+			return nil, ErrInvalidPDF
+		}
+
+		w[i] = int(width)
+	}
+
+	index, ok := stream.dict["Index"].(pdfArray)
+	if !ok {
+		size, _ := stream.dict["Size"].(int64)
+		index = pdfArray{int64(0), size}
+	}
+
+	entrySize := w[0] + w[1] + w[2]
+	if entrySize == 0 {
+		return nil, ErrInvalidPDF
+	}
+
+	field := func(entry []byte, i int) int64 {
+		var n int64
+
+		for _, b := range entry[:w[i]] {
+			n = n<<8 | int64(b)
+		}
+
+		return n
+	}
+
+	for i := 0; i+1 < len(index); i += 2 {
+		start, ok1 := index[i].(int64)
+		count, ok2 := index[i+1].(int64)
+
+		if !ok1 || !ok2 || start < 0 || count < 0 || start+count > maxPDFObjects {
+			return nil, ErrInvalidPDF
+		}
+
+		for j := range count {
+			if len(data) < entrySize {
+				return stream.dict, nil
+			}
+
+			entry := data[:entrySize]
+			data = data[entrySize:]
+
+			kind := int64(1)
+			if w[0] > 0 {
+				kind = field(entry, 0)
+			}
+
+			second := field(entry[w[0]:], 1)
+			third := field(entry[w[0]+w[1]:], 2)
+
+			num := int(start + j)
+
+			if _, exists := p.xref[num]; exists {
+				continue
+			}
+
+			switch kind {
+			case 1:
+				p.xref[num] = pdfXref{offset: second}
+			case 2:
+				p.xref[num] = pdfXref{stream: int(second), index: int(third)}
+			}
+		}
+	}
+
+	return stream.dict, nil
+}
+
+// streamData reads and decodes the data of a stream. Only the filters used
+// for cross-reference, object and content streams are supported.
+func (p *pdfReader) streamData(stream pdfStream) ([]byte, error) {
+	length, ok := p.resolve(stream.dict["Length"]).(int64)
+	if !ok || length < 0 || length > maxPDFStream || stream.offset+length > p.size {
+		return nil, ErrInvalidPDF
+	}
+
+	data := make([]byte, length)
+
+	if _, err := p.r.ReadAt(data, stream.offset); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	filter := p.resolve(stream.dict["Filter"])
+	parms, _ := p.resolve(stream.dict["DecodeParms"]).(pdfDict)
+
+	if filters, ok := filter.(pdfArray); ok {
+		if len(filters) > 1 {
+			return nil, ErrInvalidPDF
+		}
+
+		filter = nil
+		if len(filters) == 1 {
+			filter = filters[0]
+		}
+
+		if decodeParms, ok := p.resolve(stream.dict["DecodeParms"]).(pdfArray); ok && len(decodeParms) == 1 {
+			parms, _ = p.resolve(decodeParms[0]).(pdfDict)
+		}
+	}
+
+	switch filter {
+	case nil:
+		return data, nil
+	case pdfName("FlateDecode"):
+	default:
+		return nil, ErrInvalidPDF
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = io.ReadAll(io.LimitReader(zr, maxPDFStream))
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+
+	return unpredict(data, parms)
+}
+
+// unpredict reverses the PNG predictors commonly applied to
+// cross-reference streams.
+func unpredict(data []byte, parms pdfDict) ([]byte, error) {
+	predictor, _ := parms["Predictor"].(int64)
+	if predictor < 10 {
+		return data, nil
+	}
+
+	columns, ok := parms["Columns"].(int64)
+	if !ok {
+		columns = 1
+	}
+
+	if columns <= 0 || columns > maxPDFStream {
+		return nil, ErrInvalidPDF
+	}
+
+	rowLength := int(columns) + 1
+	previous := make([]byte, columns)
+
+	var out []byte
+
+	for len(data) >= rowLength {
+		row := data[1:rowLength]
+
+		switch data[0] {
+		case 0:
+		case 1:
+			for i := 1; i < len(row); i++ {
+				row[i] += row[i-1]
+			}
+		case 2:
+			for i := range row {
+				row[i] += previous[i]
+			}
+		default:
+			return nil, ErrInvalidPDF
+		}
+
+		out = append(out, row...)
+		previous = row
+		data = data[rowLength:]
+	}
+
+	return out, nil
+}
+
+// object returns the value of the numbered object, or nil if it is missing.
+func (p *pdfReader) object(num int) any {
+	if v, ok := p.objects[num]; ok {
+		return v
+	}
+
+	// Cache a placeholder first, guarding against reference loops.
+	p.objects[num] = nil
+
+	entry, ok := p.xref[num]
+	if !ok {
+		return nil
+	}
+
+	var v any
+	var err error
+
+	if entry.stream != 0 {
+		v, err = p.streamObject(entry.stream, entry.index)
+	} else if entry.offset > 0 && entry.offset < p.size {
+		v, err = p.lexer(entry.offset).object()
+	}
+
+	if err != nil {
+		return nil
+	}
+
+	p.objects[num] = v
+
+	return v
+}
+
+// streamObject returns the object at index within an object stream.
+func (p *pdfReader) streamObject(num, index int) (any, error) {
+	stream, ok := p.object(num).(pdfStream)
+	if !ok {
+		return nil, ErrInvalidPDF
+	}
+
+	data, ok := p.streams[num]
+	if !ok {
+		var err error
+
+		data, err = p.streamData(stream)
+		if err != nil {
+			return nil, err
+		}
+
+		p.streams[num] = data
+	}
+
+	first, _ := stream.dict["First"].(int64)
+	count, _ := stream.dict["N"].(int64)
+
+	if first <= 0 || first > int64(len(data)) || index < 0 || int64(index) >= count {
+		return nil, ErrInvalidPDF
+	}
+
+	// The stream begins with pairs of object numbers and offsets.
+	l := newPDFLexer(bytes.NewReader(data[:first]), 0)
+
+	var offset int64
+
+	for i := 0; i <= index; i++ {
+		if _, err := l.value(); err != nil {
+			return nil, err
+		}
+
+		v, err := l.value()
+		if err != nil {
+			return nil, err
+		}
+
+		offset, _ = v.(int64)
+	}
+
+	if offset < 0 || first+offset < first || first+offset >= int64(len(data)) {
+		return nil, ErrInvalidPDF
+	}
+
+	return newPDFLexer(bytes.NewReader(data[first+offset:]), 0).value()
+}
+
+// resolve follows v if it is a reference.
+func (p *pdfReader) resolve(v any) any {
+	if ref, ok := v.(pdfRef); ok {
+		return p.object(ref.num)
+	}
+
+	return v
+}
+
+func (p *pdfReader) dict(v any) pdfDict {
+	switch v := p.resolve(v).(type) {
+	case pdfDict:
+		return v
+	case pdfStream:
+		return v.dict
+	default:
+		return nil
+	}
+}
+
+// pages returns the dictionary of each page in order, along with the
+// resources it inherits from the page tree if it has none of its own.
+func (p *pdfReader) pages() []pdfDict {
+	var pages []pdfDict
+
+	seen := make(map[pdfRef]bool)
+
+	var walk func(node any, resources any, depth int)
+
+	walk = func(node any, resources any, depth int) {
+		if depth > maxPDFDepth {
+			return
+		}
+
+		if ref, ok := node.(pdfRef); ok {
+			if seen[ref] {
+				return
+			}
+
+			seen[ref] = true
+		}
+
+		dict := p.dict(node)
+		if dict == nil {
+			return
+		}
+
+		if own, ok := dict["Resources"]; ok {
+			resources = own
+		}
+
+		if kids, ok := p.resolve(dict["Kids"]).(pdfArray); ok {
+			for _, kid := range kids {
+				walk(kid, resources, depth+1)
+			}
+
+			return
+		}
+
+		page := maps.Clone(dict)
+		page["Resources"] = resources
+
+		pages = append(pages, page)
+	}
+
+	root := p.dict(p.trailer["Root"])
+	if root != nil {
+		walk(root["Pages"], nil, 0)
+	}
+
+	return pages
+}
+
+// pdfImageFormats maps the filters of image XObjects onto the formats of
+// their data, with other images reported as raw pdf images.
+var pdfImageFormats = map[pdfName]string{
+	"DCTDecode":      "jpeg",
+	"JPXDecode":      "jp2",
+	"JBIG2Decode":    "jbig2",
+	"CCITTFaxDecode": "ccitt",
+}
+
+// contentData decodes the content of a page or form, which may be a single
+// stream or an array of streams to be concatenated.
+func (p *pdfReader) contentData(content any) ([]byte, error) {
+	switch v := p.resolve(content).(type) {
+	case nil:
+		return nil, nil
+	case pdfStream:
+		return p.streamData(v)
+	case pdfArray:
+		var data []byte
+
+		for _, part := range v {
+			stream, ok := p.resolve(part).(pdfStream)
+			if !ok {
+				return nil, ErrInvalidPDF
+			}
+
+			partData, err := p.streamData(stream)
+			if err != nil {
+				return nil, err
+			}
+
+			// Operators may not span streams, so the parts are only joined
+			// by whitespace.
+			data = append(append(data, partData...), '\n')
+		}
+
+		return data, nil
+	default:
+		return nil, ErrInvalidPDF
+	}
+}
+
+// drawnNames returns the names of the XObjects painted by the Do operators
+// of a content stream, in the order in which they are first used.
+func drawnNames(content []byte) ([]pdfName, error) {
+	l := newPDFLexer(bytes.NewReader(content), 0)
+
+	var names []pdfName
+	var operand any
+
+	seen := make(map[pdfName]bool)
+
+	for {
+		v, err := l.value()
+		if errors.Is(err, io.EOF) {
+			return names, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch v {
+		case "Do":
+			if name, ok := operand.(pdfName); ok && !seen[name] {
+				seen[name] = true
+
+				names = append(names, name)
+			}
+		case "ID":
+			if err := skipInlineImage(l); err != nil {
+				return nil, err
+			}
+		}
+
+		operand = v
+	}
+}
+
+// skipInlineImage skips the data of an inline image, which follows the ID
+// operator and runs until an EI operator surrounded by whitespace.
+func skipInlineImage(l *pdfLexer) error {
+	// A single whitespace byte separates ID from the data.
+	if _, err := l.readByte(); err != nil {
+		return err
+	}
+
+	var window [3]byte
+
+	for {
+		c, err := l.readByte()
+		if err != nil {
+			return err
+		}
+
+		window[0], window[1], window[2] = window[1], window[2], c
+
+		if isPDFSpace(window[0]) && window[1] == 'E' && window[2] == 'I' {
+			next, err := l.peek()
+			if errors.Is(err, io.EOF) || (err == nil && (isPDFSpace(next) || isPDFDelimiter(next))) {
+				return nil
+			}
+		}
+	}
+}
+
+// images appends the image XObjects drawn by content to images, descending
+// into the form XObjects it draws, which have content and usually resources
+// of their own. If the content cannot be decoded, such as when it uses an
+// unsupported filter, every image in resources is reported instead.
+func (p *pdfReader) images(images []imageData, resources, content any, prefix string, data imageData, seen map[pdfRef]bool, depth int) []imageData {
+	if depth > maxPDFDepth {
+		return images
+	}
+
+	xobjects := p.dict(p.dict(resources)["XObject"])
+
+	var names []pdfName
+
+	decoded, err := p.contentData(content)
+	if err == nil {
+		names, err = drawnNames(decoded)
+	}
+
+	if err != nil {
+		names = slices.Sorted(maps.Keys(xobjects))
+	}
+
+	for _, name := range names {
+		ref := xobjects[name]
+
+		stream, ok := p.resolve(ref).(pdfStream)
+		if !ok {
+			continue
+		}
+
+		switch stream.dict["Subtype"] {
+		case pdfName("Image"):
+			width, okWidth := p.resolve(stream.dict["Width"]).(int64)
+			height, okHeight := p.resolve(stream.dict["Height"]).(int64)
+
+			if !okWidth || !okHeight || width <= 0 || height <= 0 {
+				continue
+			}
+
+			image := imageData{
+				name:   data.name + archiveSeparator + prefix + string(name),
+				width:  int(width),
+				height: int(height),
+				format: "pdf",
+				mtime:  data.mtime,
+			}
+
+			if length, ok := p.resolve(stream.dict["Length"]).(int64); ok {
+				image.size = length
+			}
+
+			filter := p.resolve(stream.dict["Filter"])
+			if filters, ok := filter.(pdfArray); ok && len(filters) > 0 {
+				filter = p.resolve(filters[len(filters)-1])
+			}
+
+			if filterName, ok := filter.(pdfName); ok && pdfImageFormats[filterName] != "" {
+				image.format = pdfImageFormats[filterName]
+			}
+
+			images = append(images, image)
+		case pdfName("Form"):
+			// Forms may draw themselves, so those being descended into are
+			// tracked to avoid loops.
+			r, isRef := ref.(pdfRef)
+			if isRef && seen[r] {
+				continue
+			}
+
+			if isRef {
+				seen[r] = true
+			}
+
+			// Forms without resources of their own use those of the page.
+			formResources := resources
+			if own, ok := stream.dict["Resources"]; ok {
+				formResources = own
+			}
+
+			images = p.images(images, formResources, stream, prefix+string(name)+"/", data, seen, depth+1)
+
+			if isRef {
+				delete(seen, r)
+			}
+		}
+	}
+
+	return images
+}
+
+// pdfImages reports the image XObjects drawn on each page of a PDF file,
+// named after the page and resource name, e.g. report.pdf!/page3/Im1. An
+// image drawn several times on a page is reported once. Images drawn within
+// form XObjects include the form in their name, and inline images are not
+// reported.
+func pdfImages(r io.ReadSeeker, data imageData) ([]imageData, error) {
+	at, ok := r.(io.ReaderAt)
+	if !ok {
+		return nil, ErrInvalidPDF
+	}
+
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := newPDFReader(at, size)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []imageData
+
+	for i, page := range p.pages() {
+		images = p.images(images, page["Resources"], page["Contents"], fmt.Sprintf("page%d/", i+1), data, make(map[pdfRef]bool), 0)
+	}
+
+	return images, nil
+}
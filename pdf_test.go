@@ -0,0 +1,316 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const (
+	testPDFCatalog = "<< /Type /Catalog /Pages 2 0 R >>"
+	testPDFPages   = "<< /Type /Pages /Kids [3 0 R] /Count 1 >>"
+	testPDFPage    = "<< /Type /Page /Parent 2 0 R /Resources << /XObject << /Im1 4 0 R >> >> /Contents 5 0 R >>"
+	testPDFImage   = "<< /Type /XObject /Subtype /Image /Width 640 /Height 480 /Filter /DCTDecode /Length 4 >>\nstream\n\xff\xd8\xff\xd9\nendstream"
+)
+
+var testPDFContent = testPDFStream("", "q 640 0 0 480 0 0 cm /Im1 Do Q")
+
+// testPDFStream returns a stream object holding data, compressed if filter
+// is FlateDecode.
+func testPDFStream(filter, data string) string {
+	if filter == "FlateDecode" {
+		var b bytes.Buffer
+
+		zw := zlib.NewWriter(&b)
+		zw.Write([]byte(data))
+		zw.Close()
+
+		data = b.String()
+	}
+
+	if filter != "" {
+		filter = "/Filter /" + filter + " "
+	}
+
+	return fmt.Sprintf("<< %s/Length %d >>\nstream\n%s\nendstream", filter, len(data), data)
+}
+
+// testPDFImageSized returns an image XObject with the given dimensions.
+func testPDFImageSized(width, height int) string {
+	return strings.Replace(testPDFImage, "/Width 640 /Height 480", fmt.Sprintf("/Width %d /Height %d", width, height), 1)
+}
+
+// testPDF assembles a PDF from objects numbered from 1, listed in a
+// cross-reference table. If xref is not empty, it replaces the generated
+// table and trailer.
+func testPDF(xref string, objects ...string) []byte {
+	var b bytes.Buffer
+
+	b.WriteString("%PDF-1.7\n")
+
+	offsets := make([]int, len(objects))
+
+	for i, object := range objects {
+		offsets[i] = b.Len()
+
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	start := b.Len()
+
+	if xref == "" {
+		fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+
+		for _, offset := range offsets {
+			fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+		}
+
+		fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\n", len(objects)+1)
+	} else {
+		b.WriteString(xref)
+	}
+
+	fmt.Fprintf(&b, "startxref\n%d\n%%%%EOF\n", start)
+
+	return b.Bytes()
+}
+
+// testObjStmPDF assembles a PDF whose catalog, page tree and page are held in
+// an object stream, located by a cross-reference stream. The page inherits
+// its resources from the page tree. If header is not
+// empty, it replaces the generated pairs of object numbers and offsets, and
+// first replaces the generated /First entry unless it is negative.
+func testObjStmPDF(header string, first int) []byte {
+	packed := []string{
+		testPDFCatalog,
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 /Resources << /XObject << /Im1 4 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 5 0 R >>",
+	}
+
+	var body bytes.Buffer
+
+	var pairs []string
+
+	for i, object := range packed {
+		pairs = append(pairs, fmt.Sprintf("%d %d", i+1, body.Len()))
+
+		body.WriteString(object + "\n")
+	}
+
+	if header == "" {
+		header = strings.Join(pairs, " ") + "\n"
+	}
+
+	if first < 0 {
+		first = len(header)
+	}
+
+	var b bytes.Buffer
+
+	b.WriteString("%PDF-1.7\n")
+
+	imageOffset := b.Len()
+	fmt.Fprintf(&b, "4 0 obj\n%s\nendobj\n", testPDFImage)
+
+	contentOffset := b.Len()
+	fmt.Fprintf(&b, "5 0 obj\n%s\nendobj\n", testPDFContent)
+
+	streamOffset := b.Len()
+	fmt.Fprintf(&b, "6 0 obj\n<< /Type /ObjStm /N %d /First %d /Length %d >>\nstream\n%s%s\nendstream\nendobj\n",
+		len(packed), first, len(header)+body.Len(), header, body.String())
+
+	entry := func(kind byte, second uint32, third uint16) []byte {
+		e := []byte{kind}
+		e = binary.BigEndian.AppendUint32(e, second)
+
+		return binary.BigEndian.AppendUint16(e, third)
+	}
+
+	var entries []byte
+
+	entries = append(entries, entry(0, 0, 65535)...)
+
+	for i := range packed {
+		entries = append(entries, entry(2, 6, uint16(i))...)
+	}
+
+	entries = append(entries, entry(1, uint32(imageOffset), 0)...)
+	entries = append(entries, entry(1, uint32(contentOffset), 0)...)
+	entries = append(entries, entry(1, uint32(streamOffset), 0)...)
+
+	xrefOffset := b.Len()
+	entries = append(entries, entry(1, uint32(xrefOffset), 0)...)
+
+	fmt.Fprintf(&b, "7 0 obj\n<< /Type /XRef /Size 8 /W [1 4 2] /Root 1 0 R /Length %d >>\nstream\n%s\nendstream\nendobj\n",
+		len(entries), entries)
+	fmt.Fprintf(&b, "startxref\n%d\n%%%%EOF\n", xrefOffset)
+
+	return b.Bytes()
+}
+
+func TestPDFImages(t *testing.T) {
+	selfForm := "<< /Type /XObject /Subtype /Form /Resources << /XObject << /Fm0 4 0 R >> >> /Length 7 >>\nstream\n/Fm0 Do\nendstream"
+	twoImages := "<< /Type /Page /Resources << /XObject << /Im1 4 0 R /Im2 6 0 R >> >> /Contents 5 0 R >>"
+
+	page := func(objects ...string) []byte {
+		return testPDF("", append([]string{testPDFCatalog, testPDFPages}, objects...)...)
+	}
+
+	tests := []struct {
+		name  string
+		input []byte
+		want  []string
+	}{
+		{"xref table", page(testPDFPage, testPDFImage, testPDFContent), []string{"doc.pdf!/page1/Im1 640x480 jpeg"}},
+		{"xref stream and object stream", testObjStmPDF("", -1), []string{"doc.pdf!/page1/Im1 640x480 jpeg"}},
+		{"inherited resources", testPDF("", testPDFCatalog,
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 /Resources << /XObject << /Im1 4 0 R >> >> >>",
+			"<< /Type /Page /Parent 2 0 R /Contents 5 0 R >>", testPDFImage, testPDFContent),
+			[]string{"doc.pdf!/page1/Im1 640x480 jpeg"}},
+		{"no contents", page("<< /Type /Page /Resources << /XObject << /Im1 4 0 R >> >> >>", testPDFImage), nil},
+		{"undrawn resource", page(twoImages, testPDFImage, testPDFStream("", "/Im2 Do"), testPDFImageSized(32, 32)),
+			[]string{"doc.pdf!/page1/Im2 32x32 jpeg"}},
+		{"drawn twice", page(twoImages, testPDFImage, testPDFStream("", "q /Im2 Do Q q /Im1 Do Q q /Im2 Do Q"), testPDFImageSized(32, 32)),
+			[]string{"doc.pdf!/page1/Im2 32x32 jpeg", "doc.pdf!/page1/Im1 640x480 jpeg"}},
+		{"compressed content", page(testPDFPage, testPDFImage, testPDFStream("FlateDecode", "/Im1 Do")), []string{"doc.pdf!/page1/Im1 640x480 jpeg"}},
+		{"contents array", page(strings.Replace(twoImages, "5 0 R", "[5 0 R 7 0 R]", 1), testPDFImage, testPDFStream("", "q /Im1"),
+			testPDFImageSized(32, 32), testPDFStream("", "Do Q /Im2 Do")),
+			[]string{"doc.pdf!/page1/Im1 640x480 jpeg", "doc.pdf!/page1/Im2 32x32 jpeg"}},
+		{"inline image", page(twoImages, testPDFImage, testPDFStream("", "BI /W 4 /H 2 /CS /G /BPC 8 ID /Im2 Do\nEI /Im1 Do"), testPDFImageSized(32, 32)),
+			[]string{"doc.pdf!/page1/Im1 640x480 jpeg"}},
+		{"undecodable content", page(twoImages, testPDFImage, testPDFStream("LZWDecode", "/Im2 Do"), testPDFImageSized(32, 32)),
+			[]string{"doc.pdf!/page1/Im1 640x480 jpeg", "doc.pdf!/page1/Im2 32x32 jpeg"}},
+		{"form", page("<< /Type /Page /Resources << /XObject << /Fm1 4 0 R >> >> /Contents 5 0 R >>",
+			"<< /Type /XObject /Subtype /Form /Resources << /XObject << /Im1 6 0 R /Im2 7 0 R >> >> /Length 7 >>\nstream\n/Im2 Do\nendstream",
+			testPDFStream("", "/Fm1 Do"), testPDFImage, testPDFImageSized(32, 32)),
+			[]string{"doc.pdf!/page1/Fm1/Im2 32x32 jpeg"}},
+		{"form using page resources", page("<< /Type /Page /Resources << /XObject << /Fm1 4 0 R /Im1 6 0 R >> >> /Contents 5 0 R >>",
+			"<< /Type /XObject /Subtype /Form /Length 7 >>\nstream\n/Im1 Do\nendstream",
+			testPDFStream("", "/Fm1 Do"), testPDFImage),
+			[]string{"doc.pdf!/page1/Fm1/Im1 640x480 jpeg"}},
+		{"negative object offset", testObjStmPDF("1 0 2 -99 3 0\n", -1), nil},
+		{"object offset past end", testObjStmPDF("1 0 2 100000 3 0\n", -1), nil},
+		{"object offset overflow", testObjStmPDF("1 0 2 9223372036854775807 3 0\n", -1), nil},
+		{"zero first", testObjStmPDF("", 0), nil},
+		{"first past end", testObjStmPDF("", 100000), nil},
+		{"truncated header", testObjStmPDF("1 0 2\n", -1), nil},
+		{"non-numeric offset", testObjStmPDF("1 0 2 /x 3 0\n", -1), nil},
+		{"self-referencing page tree", testPDF("", testPDFCatalog, "<< /Type /Pages /Kids [2 0 R] /Count 1 >>"), nil},
+		{"form drawing itself", page("<< /Type /Page /Resources << /XObject << /Fm0 4 0 R >> >> /Contents 5 0 R >>", selfForm, testPDFStream("", "/Fm0 Do")), nil},
+		{"missing dimensions", page(testPDFPage, strings.Replace(testPDFImage, "/Width 640 ", "", 1), testPDFContent), nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			images, _ := pdfImages(bytes.NewReader(test.input), imageData{name: "doc.pdf"})
+
+			var got []string
+
+			for _, image := range images {
+				got = append(got, fmt.Sprintf("%s %dx%d %s", image.name, image.width, image.height, image.format))
+			}
+
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPDFImagesInvalidXref(t *testing.T) {
+	objects := []string{testPDFCatalog, testPDFPages, testPDFPage, testPDFImage, testPDFContent}
+
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{"empty", nil},
+		{"no startxref", []byte("%PDF-1.7\n%%EOF\n")},
+		{"non-numeric startxref", []byte("%PDF-1.7\nstartxref\nabc\n%%EOF\n")},
+		{"startxref past end", []byte("%PDF-1.7\nstartxref\n99999\n%%EOF\n")},
+		{"negative startxref", []byte("%PDF-1.7\nstartxref\n-5\n%%EOF\n")},
+		{"negative subsection", testPDF("xref\n-1 2\ntrailer\n<< >>\n", objects...)},
+		{"huge subsection", testPDF("xref\n0 99999999\ntrailer\n<< >>\n", objects...)},
+		{"truncated table", testPDF("xref\n0 5\n0000000000 65535 f \n", objects...)},
+		{"missing trailer", testPDF("xref\n0 1\n0000000000 65535 f \ntrailer\n", objects...)},
+		{"xref stream without type", testPDF("7 0 obj\n<< /W [1 4 2] /Length 0 >>\nstream\n\nendstream\nendobj\n", objects...)},
+		{"xref stream wide fields", testPDF("7 0 obj\n<< /Type /XRef /W [1 9 2] /Size 1 /Length 0 >>\nstream\n\nendstream\nendobj\n", objects...)},
+		{"xref stream zero width", testPDF("7 0 obj\n<< /Type /XRef /W [0 0 0] /Size 1 /Length 0 >>\nstream\n\nendstream\nendobj\n", objects...)},
+		{"xref stream bad length", testPDF("7 0 obj\n<< /Type /XRef /W [1 4 2] /Size 1 /Length 999999 >>\nstream\n\nendstream\nendobj\n", objects...)},
+		{"xref stream bad filter", testPDF("7 0 obj\n<< /Type /XRef /W [1 4 2] /Size 1 /Filter /LZWDecode /Length 0 >>\nstream\n\nendstream\nendobj\n", objects...)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			images, err := pdfImages(bytes.NewReader(test.input), imageData{name: "doc.pdf"})
+			if err == nil {
+				t.Errorf("got %d images and no error", len(images))
+			}
+		})
+	}
+}
+
+func TestPDFImagesPrevLoop(t *testing.T) {
+	objects := []string{testPDFCatalog, testPDFPages, testPDFPage, testPDFImage, testPDFContent}
+
+	input := testPDF("", objects...)
+	start := bytes.LastIndex(input, []byte("xref\n"))
+
+	// Point the trailer's /Prev back at its own table.
+	input = bytes.Replace(input, []byte("/Root 1 0 R"), fmt.Appendf(nil, "/Root 1 0 R /Prev %d", start), 1)
+
+	images, err := pdfImages(bytes.NewReader(input), imageData{name: "doc.pdf"})
+	if err != nil || len(images) != 1 {
+		t.Errorf("got %d images and error %v, want 1 image", len(images), err)
+	}
+}
+
+func TestUnpredict(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  []byte
+		parms pdfDict
+		want  []byte
+		err   bool
+	}{
+		{"no predictor", []byte{1, 2, 3}, pdfDict{}, []byte{1, 2, 3}, false},
+		{"none", []byte{0, 1, 2}, pdfDict{"Predictor": int64(12), "Columns": int64(2)}, []byte{1, 2}, false},
+		{"sub", []byte{1, 1, 1}, pdfDict{"Predictor": int64(12), "Columns": int64(2)}, []byte{1, 2}, false},
+		{"up", []byte{0, 1, 2, 2, 1, 1}, pdfDict{"Predictor": int64(12), "Columns": int64(2)}, []byte{1, 2, 2, 3}, false},
+		{"partial row", []byte{0, 1, 2, 0}, pdfDict{"Predictor": int64(12), "Columns": int64(2)}, []byte{1, 2}, false},
+		{"unsupported row filter", []byte{4, 1, 2}, pdfDict{"Predictor": int64(12), "Columns": int64(2)}, nil, true},
+		{"zero columns", []byte{0, 1}, pdfDict{"Predictor": int64(12), "Columns": int64(0)}, nil, true},
+		{"negative columns", []byte{0, 1}, pdfDict{"Predictor": int64(12), "Columns": int64(-3)}, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := unpredict(bytes.Clone(test.data), test.parms)
+			if (err != nil) != test.err {
+				t.Fatalf("got error %v, want error %v", err, test.err)
+			}
+
+			if !test.err && !bytes.Equal(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func FuzzPDFImages(f *testing.F) {
+	f.Add(testPDF("", testPDFCatalog, testPDFPages, testPDFPage, testPDFImage, testPDFContent))
+	f.Add(testObjStmPDF("", -1))
+	f.Add(testObjStmPDF("1 0 2 -99 3 0\n", -1))
+
+	f.Fuzz(func(t *testing.T, input []byte) {
+		pdfImages(bytes.NewReader(input), imageData{name: "doc.pdf"})
+	})
+}
@@ -0,0 +1,18 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var pixelsCmd = &cobra.Command{
+	Use:   "pixels",
+	Short: "Filter images by area",
+}
+
+func init() {
+	rootCmd.AddCommand(pixelsCmd)
+}
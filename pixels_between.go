@@ -0,0 +1,35 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var pixelsBetweenCmd = &cobra.Command{
+	Use:   "between <minimum pixel count> <maximum pixel count> [path1] ...[pathN]",
+	Short: "Filter images by area",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, err := rangeFilter(areaField, args[0], args[1])
+		if err != nil {
+			return err
+		}
+
+		err = imageSizes(cmd, filter, args[2:])
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	pixelsBetweenCmd.Flags().BoolVar(&exclusiveMin, "exclusive-min", false, "do not match files equal to the minimum")
+	pixelsBetweenCmd.Flags().BoolVar(&exclusiveMax, "exclusive-max", false, "do not match files equal to the maximum")
+
+	pixelsCmd.AddCommand(pixelsBetweenCmd)
+}
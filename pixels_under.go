@@ -0,0 +1,32 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var pixelsUnderCmd = &cobra.Command{
+	Use:   "under <pixel count> [path1] ...[pathN]",
+	Short: "Filter images by area",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, err := thresholdFilter(areaField, less, args[0])
+		if err != nil {
+			return err
+		}
+
+		err = imageSizes(cmd, filter, args[1:])
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	pixelsCmd.AddCommand(pixelsUnderCmd)
+}
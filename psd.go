@@ -0,0 +1,60 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+var (
+	ErrInvalidPSD = errors.New("invalid photoshop header")
+)
+
+const (
+	psdHeaderLength = 26
+
+	// Photoshop limits documents to 30,000 pixels per side, and large
+	// documents (PSB) to 300,000.
+	maxPSDSize = 30000
+	maxPSBSize = 300000
+)
+
+// psdDimensions reads the canvas size of a Photoshop document from its file
+// header, for both PSD (version 1) and PSB (version 2) files.
+func psdDimensions(r io.ReadSeeker, data *imageData) error {
+	var header [psdHeaderLength]byte
+
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return err
+	}
+
+	if string(header[:4]) != "8BPS" {
+		return ErrInvalidPSD
+	}
+
+	var limit uint32
+
+	switch binary.BigEndian.Uint16(header[4:]) {
+	case 1:
+		limit = maxPSDSize
+	case 2:
+		limit = maxPSBSize
+	default:
+		return ErrInvalidPSD
+	}
+
+	height := binary.BigEndian.Uint32(header[14:])
+	width := binary.BigEndian.Uint32(header[18:])
+
+	if width == 0 || height == 0 || width > limit || height > limit {
+		return ErrInvalidPSD
+	}
+
+	data.width, data.height = int(width), int(height)
+
+	return nil
+}
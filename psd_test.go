@@ -0,0 +1,61 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func testPSD(version uint16, width, height uint32) []byte {
+	header := []byte("8BPS")
+	header = binary.BigEndian.AppendUint16(header, version)
+	header = append(header, make([]byte, 8)...)
+	header = binary.BigEndian.AppendUint32(header, height)
+	header = binary.BigEndian.AppendUint32(header, width)
+
+	return append(header, 0, 8, 0, 3)
+}
+
+func TestPsdDimensions(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		width  int
+		height int
+		err    bool
+	}{
+		{"psd", testPSD(1, 1920, 1080), 1920, 1080, false},
+		{"psb", testPSD(2, 100000, 50000), 100000, 50000, false},
+		{"psd too large", testPSD(1, 100000, 50000), 0, 0, true},
+		{"zero width", testPSD(1, 0, 1080), 0, 0, true},
+		{"bad version", testPSD(3, 1920, 1080), 0, 0, true},
+		{"truncated", testPSD(1, 1920, 1080)[:20], 0, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var data imageData
+
+			err := psdDimensions(bytes.NewReader(test.input), &data)
+			if test.err {
+				if err == nil {
+					t.Errorf("got %dx%d, want error", data.width, data.height)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if data.width != test.width || data.height != test.height {
+				t.Errorf("got %dx%d, want %dx%d", data.width, data.height, test.width, test.height)
+			}
+		})
+	}
+}
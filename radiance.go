@@ -0,0 +1,76 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidHDR = errors.New("invalid radiance hdr header")
+)
+
+// maxHDRHeader bounds how much of a Radiance file is read looking for the
+// resolution line which ends its header.
+const maxHDRHeader = 64 << 10
+
+// hdrDimensions reads the resolution line following the header of a Radiance
+// RGBE file, e.g. "-Y 768 +X 1024". The X and Y values give the width and
+// height, whichever order they appear in.
+func hdrDimensions(r io.ReadSeeker, data *imageData) error {
+	br := bufio.NewReader(io.LimitReader(r, maxHDRHeader))
+
+	// The header ends with an empty line.
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return err
+		}
+
+		if strings.TrimRight(line, "\r\n") == "" {
+			break
+		}
+	}
+
+	line, err := br.ReadString('\n')
+	if err != nil {
+		return err
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) != 4 {
+		return ErrInvalidHDR
+	}
+
+	var width, height int
+
+	for i := 0; i < 4; i += 2 {
+		value, err := strconv.Atoi(fields[i+1])
+		if err != nil || value <= 0 {
+			return ErrInvalidHDR
+		}
+
+		switch fields[i] {
+		case "+X", "-X":
+			width = value
+		case "+Y", "-Y":
+			height = value
+		default:
+			return ErrInvalidHDR
+		}
+	}
+
+	if width == 0 || height == 0 {
+		return ErrInvalidHDR
+	}
+
+	data.width, data.height = width, height
+
+	return nil
+}
@@ -0,0 +1,53 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHdrDimensions(t *testing.T) {
+	const header = "#?RADIANCE\nFORMAT=32-bit_rle_rgbe\nEXPOSURE=1.0\n\n"
+
+	tests := []struct {
+		name   string
+		input  string
+		width  int
+		height int
+		err    bool
+	}{
+		{"standard orientation", header + "-Y 768 +X 1024\n", 1024, 768, false},
+		{"x first", header + "+X 1024 -Y 768\n", 1024, 768, false},
+		{"crlf", strings.ReplaceAll(header, "\n", "\r\n") + "-Y 768 +X 1024\r\n", 1024, 768, false},
+		{"bad axis", header + "-Y 768 +Z 1024\n", 0, 0, true},
+		{"same axis twice", header + "-Y 768 +Y 1024\n", 0, 0, true},
+		{"truncated header", "#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n", 0, 0, true},
+		{"truncated resolution", header + "-Y 768", 0, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var data imageData
+
+			err := hdrDimensions(strings.NewReader(test.input), &data)
+			if test.err {
+				if err == nil {
+					t.Errorf("got %dx%d, want error", data.width, data.height)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if data.width != test.width || data.height != test.height {
+				t.Errorf("got %dx%d, want %dx%d", data.width, data.height, test.width, test.height)
+			}
+		})
+	}
+}
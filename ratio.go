@@ -0,0 +1,20 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var ratioCmd = &cobra.Command{
+	Use:   "ratio",
+	Short: "Filter images by aspect ratio",
+}
+
+func init() {
+	ratioCmd.PersistentFlags().Float64VarP(&tolerance, "tolerance", "t", 0, "treat aspect ratios within this distance of the target as equal")
+
+	rootCmd.AddCommand(ratioCmd)
+}
@@ -0,0 +1,32 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var ratioIsCmd = &cobra.Command{
+	Use:   "is <ratio> [path1] ...[pathN]",
+	Short: "Filter images by aspect ratio",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, err := ratioFilter(equal, args[0])
+		if err != nil {
+			return err
+		}
+
+		err = imageSizes(cmd, filter, args[1:])
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	ratioCmd.AddCommand(ratioIsCmd)
+}
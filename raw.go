@@ -0,0 +1,337 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrInvalidRaw           = errors.New("no full-resolution image found in raw file")
+	ErrInvalidRawDimensions = errors.New("raw dimensions must be one of sensor or crop")
+)
+
+var rawDimensionsChoices = []string{"sensor", "crop"}
+
+const (
+	tiffCompression     = 259
+	tiffMake            = 271
+	tiffStripOffsets    = 273
+	tiffStripByteCounts = 279
+	tiffSubIFDs         = 330
+	dngVersion          = 0xc612
+	dngDefaultCropSize  = 0xc620
+	sonySR2Private      = 0xc634
+
+	// Panasonic RW2 files store the sensor size and borders in private tags
+	// in place of the usual width and length.
+	rw2SensorWidth  = 0x02
+	rw2SensorHeight = 0x03
+	rw2TopBorder    = 0x04
+	rw2LeftBorder   = 0x05
+	rw2BottomBorder = 0x06
+	rw2RightBorder  = 0x07
+
+	// maxSubIFDDepth bounds how deeply nested SubIFDs are followed.
+	maxSubIFDDepth = 4
+
+	// maxJPEGStripScan bounds how much of a JPEG-compressed strip is read
+	// looking for its frame header.
+	maxJPEGStripScan = 64 << 10
+)
+
+// rawExtensions maps the extensions of TIFF-based RAW formats which share
+// the standard TIFF signature onto their format names. They are only used
+// when a file's IFD0 does not identify it.
+var rawExtensions = map[string]string{
+	".arw": "arw",
+	".dng": "dng",
+	".nef": "nef",
+	".nrw": "nef",
+	".sr2": "arw",
+	".srf": "arw",
+}
+
+// isCR2 reports whether header is that of a CR2 file, which carries its own
+// marker after the TIFF header.
+func isCR2(header []byte) bool {
+	return len(header) >= 11 && string(header[8:11]) == "CR\x02"
+}
+
+// sniffRaw distinguishes TIFF-based RAW files from plain TIFFs, returning an
+// empty string for the latter. IFD0 is checked first: DNG files carry a
+// DNGVersion tag, while Nikon and Sony RAW files name the camera maker and
+// point to the sensor image through SubIFDs (or, for Sony, their private
+// SR2 data). Files which do not match are recognised by extension instead.
+func sniffRaw(r io.ReadSeeker, name string) string {
+	if format := sniffRawIFD(r); format != "" {
+		return format
+	}
+
+	return rawExtensions[strings.ToLower(filepath.Ext(name))]
+}
+
+func sniffRawIFD(r io.ReadSeeker) string {
+	t, err := newTIFFReader(r)
+	if err != nil {
+		return ""
+	}
+
+	dir, err := t.readIFD(t.first)
+	if err != nil {
+		return ""
+	}
+
+	if _, ok := dir.entries[dngVersion]; ok {
+		return "dng"
+	}
+
+	var maker string
+	if e, ok := dir.entries[tiffMake]; ok && e.kind == 2 {
+		maker = strings.ToUpper(strings.TrimRight(string(e.value), "\x00 "))
+	}
+
+	_, hasSubIFDs := dir.entries[tiffSubIFDs]
+	_, hasSR2 := dir.entries[sonySR2Private]
+
+	switch {
+	case strings.HasPrefix(maker, "NIKON") && hasSubIFDs:
+		return "nef"
+	case strings.HasPrefix(maker, "SONY") && (hasSubIFDs || hasSR2):
+		return "arw"
+	default:
+		return ""
+	}
+}
+
+// validRawDimensions reports whether the value of --raw-dimensions is
+// recognised.
+func validRawDimensions() bool {
+	for _, choice := range rawDimensionsChoices {
+		if rawSize == choice {
+			return true
+		}
+	}
+
+	return false
+}
+
+type rawImage struct {
+	width, height         int
+	cropWidth, cropHeight int
+}
+
+// rawIFDs reads the IFD chain of a RAW file along with any SubIFDs, which is
+// where most formats keep the full-resolution image.
+func rawIFDs(t *tiffReader) ([]*ifd, error) {
+	dirs, err := t.chain(t.first)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int64]bool)
+
+	for _, dir := range dirs {
+		seen[dir.offset] = true
+	}
+
+	pending := dirs
+
+	for depth := 0; depth < maxSubIFDDepth && len(pending) > 0; depth++ {
+		var next []*ifd
+
+		for _, dir := range pending {
+			e, ok := dir.entries[tiffSubIFDs]
+			if !ok {
+				continue
+			}
+
+			for i := range int(e.count) {
+				offset, ok := t.uint(e, i)
+				if !ok || offset == 0 || seen[int64(offset)] || len(dirs) >= maxIFDs {
+					continue
+				}
+
+				seen[int64(offset)] = true
+
+				sub, err := t.readIFD(int64(offset))
+				if err != nil {
+					continue
+				}
+
+				dirs = append(dirs, sub)
+				next = append(next, sub)
+			}
+		}
+
+		pending = next
+	}
+
+	return dirs, nil
+}
+
+// jpegStripDimensions reads the frame header of a JPEG-compressed strip, as
+// used by CR2 files, whose raw IFD has no width or length tags. Lossless
+// JPEG frames interleave several sensor columns into each component.
+func (t *tiffReader) jpegStripDimensions(dir *ifd) (int, int, bool) {
+	offset, ok := t.tag(dir, tiffStripOffsets)
+	if !ok {
+		return 0, 0, false
+	}
+
+	length := uint64(maxJPEGStripScan)
+	if count, ok := t.tag(dir, tiffStripByteCounts); ok {
+		length = min(length, count)
+	}
+
+	strip, err := t.readAt(int64(offset), int(length))
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return 0, 0, false
+	}
+
+	if len(strip) < 4 || strip[0] != 0xff || strip[1] != 0xd8 {
+		return 0, 0, false
+	}
+
+	for pos := 2; pos+4 <= len(strip); {
+		if strip[pos] != 0xff {
+			return 0, 0, false
+		}
+
+		marker := strip[pos+1]
+		size := int(strip[pos+2])<<8 | int(strip[pos+3])
+
+		switch marker {
+		case 0xc0, 0xc1, 0xc2, 0xc3:
+			if pos+10 > len(strip) {
+				return 0, 0, false
+			}
+
+			height := int(strip[pos+5])<<8 | int(strip[pos+6])
+			width := int(strip[pos+7])<<8 | int(strip[pos+8])
+			components := int(strip[pos+9])
+
+			if marker == 0xc3 {
+				width *= components
+			}
+
+			return width, height, width > 0 && height > 0
+		case 0xd9, 0xda:
+			return 0, 0, false
+		}
+
+		pos += 2 + size
+	}
+
+	return 0, 0, false
+}
+
+// imageSize returns the size of the image described by dir, if any, along
+// with its default crop where the file records one.
+func (t *tiffReader) imageSize(dir *ifd) (rawImage, bool) {
+	var image rawImage
+
+	width, hasWidth := t.tag(dir, tiffImageWidth)
+	height, hasHeight := t.tag(dir, tiffImageLength)
+
+	switch {
+	case hasWidth && hasHeight:
+		image.width, image.height = int(width), int(height)
+	default:
+		if sensorWidth, ok := t.tag(dir, rw2SensorWidth); ok {
+			sensorHeight, _ := t.tag(dir, rw2SensorHeight)
+
+			image.width, image.height = int(sensorWidth), int(sensorHeight)
+
+			top, _ := t.tag(dir, rw2TopBorder)
+			left, _ := t.tag(dir, rw2LeftBorder)
+			bottom, hasBottom := t.tag(dir, rw2BottomBorder)
+			right, hasRight := t.tag(dir, rw2RightBorder)
+
+			if hasBottom && hasRight && bottom > top && right > left {
+				image.cropWidth, image.cropHeight = int(right-left), int(bottom-top)
+			}
+
+			break
+		}
+
+		if compression, ok := t.tag(dir, tiffCompression); ok && (compression == 6 || compression == 7) {
+			image.width, image.height, _ = t.jpegStripDimensions(dir)
+		}
+	}
+
+	if e, ok := dir.entries[dngDefaultCropSize]; ok {
+		cropWidth, okWidth := t.number(e, 0)
+		cropHeight, okHeight := t.number(e, 1)
+
+		if okWidth && okHeight {
+			image.cropWidth, image.cropHeight = int(cropWidth+0.5), int(cropHeight+0.5)
+		}
+	}
+
+	return image, image.width > 0 && image.height > 0
+}
+
+// rawDimensions finds the full-resolution image in a camera RAW file by
+// walking its IFDs and SubIFDs, skipping reduced-resolution previews and
+// picking the largest remaining image. Where the file records a default
+// crop (as DNG does), --raw-dimensions selects between the sensor and
+// cropped sizes, and the other is noted in verbose output.
+func rawDimensions(r io.ReadSeeker, data *imageData) error {
+	t, err := newTIFFReader(r)
+	if err != nil {
+		return err
+	}
+
+	dirs, err := rawIFDs(t)
+	if err != nil {
+		return err
+	}
+
+	var best rawImage
+
+	for _, dir := range dirs {
+		if subfileType, ok := t.tag(dir, tiffNewSubfileType); ok && subfileType&1 != 0 {
+			continue
+		}
+
+		image, ok := t.imageSize(dir)
+		if !ok {
+			continue
+		}
+
+		if image.width*image.height > best.width*best.height {
+			best = image
+		}
+	}
+
+	if best.width == 0 {
+		return ErrInvalidRaw
+	}
+
+	data.width, data.height = best.width, best.height
+
+	if best.cropWidth > 0 && best.cropHeight > 0 && (best.cropWidth != best.width || best.cropHeight != best.height) {
+		if rawSize == "crop" {
+			data.width, data.height = best.cropWidth, best.cropHeight
+			data.notes = append(data.notes, fmt.Sprintf("sensor %dx%d", best.width, best.height))
+		} else {
+			data.notes = append(data.notes, fmt.Sprintf("crop %dx%d", best.cropWidth, best.cropHeight))
+		}
+	}
+
+	// The orientation of the developed image is recorded in IFD0.
+	data.orientation = 1
+
+	if value, ok := t.tag(dirs[0], exifOrientationTag); ok && value >= 1 && value <= 8 {
+		data.orientation = int(value)
+	}
+
+	return nil
+}
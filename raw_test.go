@@ -0,0 +1,128 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+	"testing"
+)
+
+// testRaw assembles a TIFF-based RAW file whose IFD0 holds the given entries
+// and points through SubIFDs to a sensor IFD stored after it. Sensor entries
+// must fit inline.
+func testRaw(ifd0 []testTIFFEntry, sensor []testTIFFEntry) []byte {
+	offset := len(testTIFF(append(slices.Clone(ifd0), testLong(tiffSubIFDs, 0))))
+
+	out := testTIFF(append(slices.Clone(ifd0), testLong(tiffSubIFDs, uint32(offset))))
+
+	out = binary.LittleEndian.AppendUint16(out, uint16(len(sensor)))
+
+	for _, entry := range sensor {
+		out = binary.LittleEndian.AppendUint16(out, entry.tag)
+		out = binary.LittleEndian.AppendUint16(out, entry.kind)
+		out = binary.LittleEndian.AppendUint32(out, entry.count)
+		out = append(out, entry.value...)
+		out = append(out, make([]byte, 4-len(entry.value))...)
+	}
+
+	return binary.LittleEndian.AppendUint32(out, 0)
+}
+
+func TestRawDimensions(t *testing.T) {
+	preview := []testTIFFEntry{
+		testLong(tiffNewSubfileType, 1),
+		testLong(tiffImageWidth, 256),
+		testLong(tiffImageLength, 171),
+		{dngVersion, 1, 4, []byte{1, 4, 0, 0}},
+	}
+
+	sensor := []testTIFFEntry{
+		testLong(tiffNewSubfileType, 0),
+		testLong(tiffImageWidth, 6000),
+		testLong(tiffImageLength, 4000),
+		{dngDefaultCropSize, 3, 2, binary.LittleEndian.AppendUint16(binary.LittleEndian.AppendUint16(nil, 5976), 3992)},
+	}
+
+	dng := testRaw(preview, sensor)
+	rotated := testRaw(append(slices.Clone(preview), testShort(exifOrientationTag, 8)), sensor[:3])
+
+	tests := []struct {
+		name        string
+		input       []byte
+		size        string
+		width       int
+		height      int
+		orientation int
+		notes       []string
+		err         bool
+	}{
+		{"sensor", dng, "sensor", 6000, 4000, 1, []string{"crop 5976x3992"}, false},
+		{"crop", dng, "crop", 5976, 3992, 1, []string{"sensor 6000x4000"}, false},
+		{"orientation", rotated, "sensor", 6000, 4000, 8, nil, false},
+		{"preview only", testTIFF(preview), "sensor", 0, 0, 0, nil, true},
+		{"truncated", dng[:30], "sensor", 0, 0, 0, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			saved := rawSize
+			t.Cleanup(func() { rawSize = saved })
+
+			rawSize = test.size
+
+			var data imageData
+
+			err := rawDimensions(bytes.NewReader(test.input), &data)
+			if test.err {
+				if err == nil {
+					t.Errorf("got %dx%d, want error", data.width, data.height)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if data.width != test.width || data.height != test.height || data.orientation != test.orientation {
+				t.Errorf("got %dx%d with orientation %d, want %dx%d with orientation %d",
+					data.width, data.height, data.orientation, test.width, test.height, test.orientation)
+			}
+
+			if !slices.Equal(data.notes, test.notes) {
+				t.Errorf("got notes %q, want %q", data.notes, test.notes)
+			}
+		})
+	}
+}
+
+func TestSniffRaw(t *testing.T) {
+	page := []testTIFFEntry{testLong(tiffImageWidth, 640), testLong(tiffImageLength, 480)}
+	sensor := []testTIFFEntry{testLong(tiffImageWidth, 6048), testLong(tiffImageLength, 4024)}
+
+	tests := []struct {
+		name  string
+		file  string
+		input []byte
+		want  string
+	}{
+		{"dng", "", testRaw([]testTIFFEntry{{dngVersion, 1, 4, []byte{1, 6, 0, 0}}}, sensor), "dng"},
+		{"nef", "renamed.tif", testRaw([]testTIFFEntry{testASCII(tiffMake, "NIKON CORPORATION")}, sensor), "nef"},
+		{"arw", "", testTIFF(append(slices.Clone(page), testASCII(tiffMake, "SONY"), testLong(sonySR2Private, 0))), "arw"},
+		{"make without sensor image", "scan.tif", testTIFF(append(slices.Clone(page), testASCII(tiffMake, "NIKON"))), ""},
+		{"extension fallback", "photo.NEF", testTIFF(page), "nef"},
+		{"plain tiff", "scan.tif", testTIFF(page), ""},
+		{"truncated", "photo.dng", testTIFF(page)[:6], "dng"},
+	}
+
+	for _, test := range tests {
+		if got := sniffRaw(bytes.NewReader(test.input), test.file); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
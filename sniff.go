@@ -0,0 +1,226 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	avif "github.com/gen2brain/avif"
+	heic "github.com/gen2brain/heic"
+	jpegxl "github.com/gen2brain/jpegxl"
+
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/webp"
+)
+
+// sniffLength is the number of bytes read from the start of each file to
+// identify its format.
+const sniffLength = 512
+
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+// A decoder reads the dimensions of an image positioned at the start of r.
+type decoder func(r io.ReadSeeker, data *imageData) error
+
+// decoders maps each format identified by sniff onto the function used to
+// read its dimensions.
+var decoders = map[string]decoder{
+	"avif": withFallback(isobmffDimensions, avif.DecodeConfig),
+	"arw":  rawDimensions,
+	"bmp":  configDecoder(bmp.DecodeConfig),
+	"cr2":  rawDimensions,
+	"cur":  iconDimensions,
+	"dds":  ddsDimensions,
+	"dng":  rawDimensions,
+	"exr":  exrDimensions,
+	"gif":  configDecoder(gif.DecodeConfig),
+	"hdr":  hdrDimensions,
+	"heic": withFallback(isobmffDimensions, heic.DecodeConfig),
+	"ico":  iconDimensions,
+	"j2k":  j2kDimensions,
+	"jp2":  jp2Dimensions,
+	"jpeg": configDecoder(jpeg.DecodeConfig),
+	"jpx":  jp2Dimensions,
+	"jxl":  withFallback(jxlDimensions, jpegxl.DecodeConfig),
+	"ktx":  ktxDimensions,
+	"ktx2": ktx2Dimensions,
+	"nef":  rawDimensions,
+	"orf":  rawDimensions,
+	"pam":  netpbmDimensions,
+	"pbm":  netpbmDimensions,
+	"pcx":  pcxDimensions,
+	"pfm":  netpbmDimensions,
+	"pgm":  netpbmDimensions,
+	"png":  configDecoder(png.DecodeConfig),
+	"ppm":  netpbmDimensions,
+	"psb":  psdDimensions,
+	"psd":  psdDimensions,
+	"qoi":  qoiDimensions,
+	"rw2":  rawDimensions,
+	"svg":  svgDimensions,
+	"tga":  tgaDimensions,
+	"tiff": tiffDimensions,
+	"webp": configDecoder(webp.DecodeConfig),
+}
+
+// configDecoder adapts an image.DecodeConfig-style function to a decoder.
+func configDecoder(decodeConfig func(io.Reader) (image.Config, error)) decoder {
+	return func(r io.ReadSeeker, data *imageData) error {
+		cfg, err := decodeConfig(r)
+		if err != nil {
+			return err
+		}
+
+		data.width, data.height = cfg.Width, cfg.Height
+
+		return nil
+	}
+}
+
+// withFallback tries a native decoder first, rewinding and falling back to
+// a full decoder if the native one cannot handle the file.
+func withFallback(native decoder, decodeConfig func(io.Reader) (image.Config, error)) decoder {
+	fallback := configDecoder(decodeConfig)
+
+	return func(r io.ReadSeeker, data *imageData) error {
+		if err := native(r, data); err == nil {
+			return nil
+		}
+
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		return fallback(r, data)
+	}
+}
+
+var (
+	avifBrands = [][]byte{[]byte("avif"), []byte("avis")}
+	heicBrands = [][]byte{
+		[]byte("heic"), []byte("heix"), []byte("heim"), []byte("heis"),
+		[]byte("hevc"), []byte("hevx"), []byte("hevm"), []byte("hevs"),
+		[]byte("mif1"), []byte("msf1"),
+	}
+)
+
+// readHeader reads up to sniffLength bytes from the start of r.
+func readHeader(r io.Reader) ([]byte, error) {
+	header := make([]byte, sniffLength)
+
+	n, err := io.ReadFull(r, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	return header[:n], nil
+}
+
+// sniff identifies an image format from the name and leading bytes of a
+// file, or returns an empty string if the format is not recognised.
+func sniff(name string, header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, []byte(pngSignature)):
+		return "png"
+	case bytes.HasPrefix(header, []byte("\xff\xd8\xff")):
+		return "jpeg"
+	case bytes.HasPrefix(header, []byte("GIF87a")), bytes.HasPrefix(header, []byte("GIF89a")):
+		return "gif"
+	case bytes.HasPrefix(header, []byte("BM")) && len(header) >= 26:
+		return "bmp"
+	case len(header) >= 12 && bytes.Equal(header[:4], []byte("RIFF")) && bytes.Equal(header[8:12], []byte("WEBP")):
+		return "webp"
+	case bytes.HasPrefix(header, []byte("II*\x00")) && isCR2(header):
+		return "cr2"
+	case bytes.HasPrefix(header, []byte("II*\x00")), bytes.HasPrefix(header, []byte("MM\x00*")),
+		bytes.HasPrefix(header, []byte("II+\x00")), bytes.HasPrefix(header, []byte("MM\x00+")):
+		return "tiff"
+	case bytes.HasPrefix(header, []byte("IIRO")), bytes.HasPrefix(header, []byte("IIRS")), bytes.HasPrefix(header, []byte("MMOR")):
+		return "orf"
+	case bytes.HasPrefix(header, []byte("IIU\x00")):
+		return "rw2"
+	case bytes.HasPrefix(header, []byte("\xff\x0a")),
+		bytes.HasPrefix(header, []byte("\x00\x00\x00\x0cJXL \r\n\x87\n")):
+		return "jxl"
+	case bytes.HasPrefix(header, []byte(jp2Signature)):
+		return sniffJP2(header)
+	case bytes.HasPrefix(header, []byte(j2kSignature)):
+		return "j2k"
+	case len(header) >= 12 && bytes.Equal(header[4:8], []byte("ftyp")):
+		return sniffBrands(header)
+	case bytes.HasPrefix(header, []byte("8BPS\x00\x01")):
+		return "psd"
+	case bytes.HasPrefix(header, []byte("8BPS\x00\x02")):
+		return "psb"
+	case bytes.HasPrefix(header, []byte("DDS ")):
+		return "dds"
+	case bytes.HasPrefix(header, ktxIdentifier):
+		return "ktx"
+	case bytes.HasPrefix(header, ktx2Identifier):
+		return "ktx2"
+	case bytes.HasPrefix(header, []byte("qoif")):
+		return "qoi"
+	case bytes.HasPrefix(header, []byte(exrMagic)):
+		return "exr"
+	case bytes.HasPrefix(header, []byte("#?RADIANCE\n")), bytes.HasPrefix(header, []byte("#?RGBE\n")):
+		return "hdr"
+	case sniffNetpbm(header) != "":
+		return sniffNetpbm(header)
+	case isPCX(header):
+		return "pcx"
+	case isIcon(header) && header[2] == 2:
+		return "cur"
+	case isIcon(header):
+		return "ico"
+	case isSVG(header):
+		return "svg"
+	case isTGA(name, header):
+		return "tga"
+	default:
+		return ""
+	}
+}
+
+// sniffBrands inspects the major and compatible brands of an ISOBMFF ftyp
+// box to distinguish AVIF from HEIC.
+func sniffBrands(header []byte) string {
+	size := int(binary.BigEndian.Uint32(header))
+	if size < 16 || size > len(header) {
+		size = len(header)
+	}
+
+	var brands [][]byte
+
+	brands = append(brands, header[8:12])
+
+	for i := 16; i+4 <= size; i += 4 {
+		brands = append(brands, header[i:i+4])
+	}
+
+	for _, candidates := range []struct {
+		format string
+		brands [][]byte
+	}{
+		{"avif", avifBrands},
+		{"heic", heicBrands},
+	} {
+		for _, brand := range brands {
+			for _, candidate := range candidates.brands {
+				if bytes.Equal(brand, candidate) {
+					return candidates.format
+				}
+			}
+		}
+	}
+
+	return ""
+}
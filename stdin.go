@@ -0,0 +1,61 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+var (
+	ErrStdinInUse = errors.New("stdin cannot be read as both an image and a file list")
+	ErrNotAnImage = errors.New("not a recognised image")
+	ErrNoMatch    = errors.New("image read from stdin did not match")
+)
+
+// stdinPath is the path which reads a single image from stdin.
+const stdinPath = "-"
+
+// scanStdin reads a single image from stdin, sending it to results if it
+// matches filter and reporting whether it did. The image is buffered in
+// memory, as decoders need to seek. As there is no file to inspect
+// afterwards, images which do not match are still described on stderr.
+func scanStdin(filter expression, results chan<- imageData) (bool, error) {
+	content, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return false, err
+	}
+
+	images, err := readImages(bytes.NewReader(content), imageData{
+		name:  stdinPath,
+		size:  int64(len(content)),
+		mtime: time.Now(),
+	}, 0)
+	if err != nil {
+		return false, err
+	}
+
+	if len(images) == 0 {
+		fmt.Fprintf(os.Stderr, "Skipping %s: %v.\n", stdinPath, ErrNotAnImage)
+	}
+
+	matched := false
+
+	for _, image := range images {
+		if filter.matches(&image) {
+			results <- image
+
+			matched = true
+		} else {
+			fmt.Fprintf(os.Stderr, "%v (%v) did not match.\n", image.name, describe(image))
+		}
+	}
+
+	return matched, nil
+}
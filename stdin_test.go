@@ -0,0 +1,140 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// testPNG returns a blank PNG image of the given size.
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	var b bytes.Buffer
+
+	if err := png.Encode(&b, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+
+	return b.Bytes()
+}
+
+// withStdin runs f with stdin reading content.
+func withStdin(t *testing.T, content []byte, f func()) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "stdin")
+
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	stdin := os.Stdin
+	os.Stdin = file
+
+	defer func() {
+		os.Stdin = stdin
+	}()
+
+	f()
+}
+
+// withFlagDefaults applies the defaults which main gives the flags read by
+// imageSizes, restoring their previous values once t finishes.
+func withFlagDefaults(t *testing.T) {
+	t.Helper()
+
+	savedConcurrency, savedIconEntry, savedMaxDepth := concurrency, iconEntry, maxDepth
+	savedKey, savedOrder, savedRawSize := key, order, rawSize
+
+	t.Cleanup(func() {
+		concurrency, iconEntry, maxDepth = savedConcurrency, savedIconEntry, savedMaxDepth
+		key, order, rawSize = savedKey, savedOrder, savedRawSize
+	})
+
+	concurrency, iconEntry, maxDepth = 16, "largest", -1
+	key, order, rawSize = "name", "ascending", "sensor"
+}
+
+func TestImageSizesStdinExitStatus(t *testing.T) {
+	withFlagDefaults(t)
+
+	dir := t.TempDir()
+
+	other := filepath.Join(dir, "other.png")
+
+	if err := os.WriteFile(other, testPNG(t, 200, 200), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	missing := filepath.Join(dir, "missing.png")
+
+	tests := []struct {
+		name   string
+		stdin  []byte
+		paths  []string
+		want   error
+		status int
+	}{
+		{"match", testPNG(t, 200, 100), []string{"-"}, nil, 0},
+		{"no match", testPNG(t, 50, 100), []string{"-"}, ErrNoMatch, 1},
+		{"no match, other path matches", testPNG(t, 50, 100), []string{"-", other}, ErrNoMatch, 1},
+		{"match, other path matches", testPNG(t, 200, 100), []string{other, "-"}, nil, 0},
+		{"not an image", []byte("not an image"), []string{"-"}, ErrNoMatch, 1},
+		{"match, other path missing", testPNG(t, 200, 100), []string{"-", missing}, fs.ErrNotExist, 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			withStdin(t, test.stdin, func() {
+				filter := newComparison(widthField, greater, 100)
+
+				err := imageSizes(&cobra.Command{}, filter, test.paths)
+				if !errors.Is(err, test.want) {
+					t.Errorf("got error %v, want %v", err, test.want)
+				}
+
+				if status := exitCode(err); status != test.status {
+					t.Errorf("got exit status %d, want %d", status, test.status)
+				}
+			})
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, 0},
+		{ErrNoMatch, 1},
+		{fmt.Errorf("scanning: %w", ErrNoMatch), 1},
+		{ErrNoFilter, 2},
+		{fs.ErrPermission, 2},
+	}
+
+	for _, test := range tests {
+		if got := exitCode(test.err); got != test.want {
+			t.Errorf("exitCode(%v) = %d, want %d", test.err, got, test.want)
+		}
+	}
+}
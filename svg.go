@@ -0,0 +1,246 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidSVG = errors.New("invalid svg")
+	ErrNoSize     = errors.New("svg has no intrinsic size")
+)
+
+// maxSVGPrologue bounds how much of an SVG is read while looking for the
+// root element, which may be preceded by comments and a DOCTYPE.
+const maxSVGPrologue = 1 << 20
+
+// svgUnits maps CSS length units onto their size in pixels, assuming the
+// default font size of 16px for font-relative units.
+var svgUnits = map[string]float64{
+	"":   1,
+	"px": 1,
+	"pt": 4.0 / 3.0,
+	"pc": 16,
+	"mm": 96 / 25.4,
+	"cm": 96 / 2.54,
+	"q":  96 / 101.6,
+	"in": 96,
+	"em": 16,
+	"ex": 8,
+}
+
+// isSVG reports whether header looks like the start of an SVG document,
+// skipping the XML declaration, comments and DOCTYPE that may precede the
+// root element. If the header ends before the root element is reached, the
+// document is accepted and left to svgDimensions, which reads up to
+// maxSVGPrologue bytes and rejects documents whose root is not an svg.
+func isSVG(header []byte) bool {
+	header = bytes.TrimPrefix(header, []byte("\xef\xbb\xbf"))
+	header = bytes.TrimLeft(header, " \t\r\n")
+
+	if !bytes.HasPrefix(header, []byte("<")) {
+		return false
+	}
+
+	for {
+		header = bytes.TrimLeft(header, " \t\r\n")
+
+		var end int
+
+		switch {
+		case len(header) == 0:
+			return true
+		case bytes.HasPrefix(header, []byte("<?")):
+			end = bytes.Index(header, []byte("?>"))
+			if end != -1 {
+				end++
+			}
+		case bytes.HasPrefix(header, []byte("<!--")):
+			end = bytes.Index(header[4:], []byte("-->"))
+			if end != -1 {
+				end += 4 + 2
+			}
+		case len(header) >= 9 && bytes.EqualFold(header[:9], []byte("<!doctype")):
+			end = doctypeEnd(header)
+		case bytes.HasPrefix(header, []byte("<")):
+			name := header[1:]
+
+			end = bytes.IndexAny(name, " \t\r\n/>")
+			if end == -1 {
+				return true
+			}
+
+			name = name[:end]
+			if i := bytes.IndexByte(name, ':'); i != -1 {
+				name = name[i+1:]
+			}
+
+			return bytes.Equal(name, []byte("svg"))
+		default:
+			return false
+		}
+
+		if end == -1 {
+			return true
+		}
+
+		header = header[end+1:]
+	}
+}
+
+// doctypeEnd returns the index of the closing bracket of the DOCTYPE at the
+// start of header, skipping any internal subset and quoted strings, or -1 if
+// it is not found.
+func doctypeEnd(header []byte) int {
+	var quote byte
+
+	depth := 0
+
+	for i, c := range header {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == '>' && depth <= 0:
+			return i
+		}
+	}
+
+	return -1
+}
+
+// parseLength converts an SVG length such as "12.5mm" to pixels. Percentages
+// are relative to the viewport and so do not give an intrinsic size.
+func parseLength(value string) (float64, bool) {
+	value = strings.TrimSpace(value)
+
+	end := 0
+
+	for end < len(value) && (isDigit(value[end]) || strings.IndexByte("+-.", value[end]) != -1) {
+		end++
+	}
+
+	// An exponent must be followed by a digit or sign, which distinguishes it
+	// from the em and ex units.
+	if end+1 < len(value) && (value[end] == 'e' || value[end] == 'E') && (isDigit(value[end+1]) || value[end+1] == '-' || value[end+1] == '+') {
+		end++
+
+		for end < len(value) && (isDigit(value[end]) || value[end] == '-' || value[end] == '+') {
+			end++
+		}
+	}
+
+	number, err := strconv.ParseFloat(value[:end], 64)
+	if err != nil || number <= 0 {
+		return 0, false
+	}
+
+	multiplier, ok := svgUnits[strings.ToLower(strings.TrimSpace(value[end:]))]
+	if !ok {
+		return 0, false
+	}
+
+	return number * multiplier, true
+}
+
+// parseViewBox returns the width and height of an SVG viewBox attribute.
+func parseViewBox(value string) (float64, float64, bool) {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\r' || r == '\n'
+	})
+
+	if len(fields) != 4 {
+		return 0, 0, false
+	}
+
+	width, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil || width <= 0 {
+		return 0, 0, false
+	}
+
+	height, err := strconv.ParseFloat(fields[3], 64)
+	if err != nil || height <= 0 {
+		return 0, 0, false
+	}
+
+	return width, height, true
+}
+
+// svgDimensions reads the intrinsic size of an SVG from the width and height
+// attributes of its root element, falling back to the viewBox and noting
+// when it does so.
+func svgDimensions(r io.ReadSeeker, data *imageData) error {
+	d := xml.NewDecoder(io.LimitReader(r, maxSVGPrologue))
+	d.Strict = false
+
+	var root xml.StartElement
+
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return ErrInvalidSVG
+		}
+
+		if start, ok := t.(xml.StartElement); ok {
+			root = start
+
+			break
+		}
+	}
+
+	if root.Name.Local != "svg" {
+		return ErrInvalidSVG
+	}
+
+	var width, height, viewWidth, viewHeight float64
+	var hasWidth, hasHeight, hasViewBox bool
+
+	for _, attr := range root.Attr {
+		if attr.Name.Space != "" {
+			continue
+		}
+
+		switch attr.Name.Local {
+		case "width":
+			width, hasWidth = parseLength(attr.Value)
+		case "height":
+			height, hasHeight = parseLength(attr.Value)
+		case "viewBox":
+			viewWidth, viewHeight, hasViewBox = parseViewBox(attr.Value)
+		}
+	}
+
+	switch {
+	case hasWidth && hasHeight:
+	case hasWidth && hasViewBox:
+		height = width * viewHeight / viewWidth
+	case hasHeight && hasViewBox:
+		width = height * viewWidth / viewHeight
+	case hasViewBox:
+		width, height = viewWidth, viewHeight
+
+		data.notes = append(data.notes, "no intrinsic size, using viewBox")
+	default:
+		return ErrNoSize
+	}
+
+	data.width, data.height = int(math.Round(width)), int(math.Round(height))
+
+	return nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestIsSVG(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{`<svg xmlns="http://www.w3.org/2000/svg"/>`, true},
+		{"\xef\xbb\xbf\n<svg>", true},
+		{`<?xml version="1.0"?><!-- <html> --><svg>`, true},
+		{`<!DOCTYPE svg PUBLIC "-//W3C//DTD SVG 1.1//EN" "http://www.w3.org/Graphics/SVG/1.1/DTD/svg11.dtd"><svg>`, true},
+		{`<!DOCTYPE svg [<!ENTITY a "<b>">]><svg>`, true},
+		{`<svg:svg xmlns:svg="http://www.w3.org/2000/svg">`, true},
+		{`<!-- an unterminated comment`, true},
+		{`<!DOCTYPE html><html><body><svg>`, false},
+		{`<svgfoo>`, false},
+		{`<?xml version="1.0"?><rss>`, false},
+		{`svg`, false},
+		{``, false},
+	}
+
+	for _, test := range tests {
+		if got := isSVG([]byte(test.header)); got != test.want {
+			t.Errorf("isSVG(%q) = %v, want %v", test.header, got, test.want)
+		}
+	}
+}
+
+func TestSvgDimensions(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		width  int
+		height int
+		err    bool
+	}{
+		{"width and height", `<svg width="100" height="50"/>`, 100, 50, false},
+		{"units", `<svg width="1in" height="72pt"/>`, 96, 96, false},
+		{"width and viewBox", `<svg width="200" viewBox="0 0 100 50"/>`, 200, 100, false},
+		{"viewBox only", `<svg viewBox="0,0,640,480"/>`, 640, 480, false},
+		{"no size", `<svg width="100%" height="100%"/>`, 0, 0, true},
+		{"not svg", `<html width="100" height="50"/>`, 0, 0, true},
+		{"truncated", `<svg width="100" hei`, 0, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var data imageData
+
+			err := svgDimensions(strings.NewReader(test.input), &data)
+			if test.err {
+				if err == nil {
+					t.Errorf("got %dx%d, want error", data.width, data.height)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if data.width != test.width || data.height != test.height {
+				t.Errorf("got %dx%d, want %dx%d", data.width, data.height, test.width, test.height)
+			}
+		})
+	}
+}
+
+func TestSvgLongPrologue(t *testing.T) {
+	prologue := `<?xml version="1.0"?><!--` + strings.Repeat(" licence text", 1000) + `-->`
+
+	tests := []struct {
+		name  string
+		input string
+		found bool
+	}{
+		{"svg root", prologue + `<svg width="100" height="50"/>`, true},
+		{"other root", prologue + `<html width="100" height="50"/>`, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := imageData{name: "long.svg"}
+
+			found, err := decodeDimensions(bytes.NewReader([]byte(test.input)), &data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if found != test.found {
+				t.Fatalf("got found %v, want %v", found, test.found)
+			}
+
+			if found && (data.format != "svg" || data.width != 100 || data.height != 50) {
+				t.Errorf("got %s %dx%d, want svg 100x50", data.format, data.width, data.height)
+			}
+		})
+	}
+}
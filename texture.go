@@ -0,0 +1,400 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrInvalidTexture = errors.New("invalid texture header")
+)
+
+const (
+	ddsHeaderLength  = 128
+	ddsDX10Length    = 20
+	ktxHeaderLength  = 64
+	ktx2HeaderLength = 48
+	qoiHeaderLength  = 14
+	tgaHeaderLength  = 18
+	tgaFooterLength  = 26
+
+	ddsCubemap      = 0x200
+	ddsDX10Cubemap  = 0x4
+	ddsFourCCFlag   = 0x4
+	ktx2BasisLZ     = 1
+	ktx2Zstandard   = 2
+	ktx2Zlib        = 3
+	ktx2Undefined   = 0
+	ktxCompressed   = 0
+	ktxLittleEndian = 0x04030201
+)
+
+var (
+	ktxIdentifier  = []byte("\xabKTX 11\xbb\r\n\x1a\n")
+	ktx2Identifier = []byte("\xabKTX 20\xbb\r\n\x1a\n")
+	tgaSignature   = []byte("TRUEVISION-XFILE.\x00")
+)
+
+// tgaExtensions lists the extensions under which TGA files without a TGA 2.0
+// footer are recognised, as the format has no signature at the start of the
+// file.
+var tgaExtensions = map[string]bool{
+	".tga":  true,
+	".icb":  true,
+	".tpic": true,
+	".vda":  true,
+	".vst":  true,
+}
+
+var ddsFourCCs = map[string]string{
+	"DXT1": "BC1",
+	"DXT2": "BC2",
+	"DXT3": "BC2",
+	"DXT4": "BC3",
+	"DXT5": "BC3",
+	"ATI1": "BC4",
+	"BC4U": "BC4",
+	"BC4S": "BC4",
+	"ATI2": "BC5",
+	"BC5U": "BC5",
+	"BC5S": "BC5",
+}
+
+// dxgiFormat names the block-compressed DXGI_FORMAT values.
+func dxgiFormat(format uint32) string {
+	switch {
+	case format >= 70 && format <= 72:
+		return "BC1"
+	case format >= 73 && format <= 75:
+		return "BC2"
+	case format >= 76 && format <= 78:
+		return "BC3"
+	case format >= 79 && format <= 81:
+		return "BC4"
+	case format >= 82 && format <= 84:
+		return "BC5"
+	case format >= 94 && format <= 96:
+		return "BC6H"
+	case format >= 97 && format <= 99:
+		return "BC7"
+	default:
+		return ""
+	}
+}
+
+// glInternalFormat names the common compressed OpenGL internal formats.
+func glInternalFormat(format uint32) string {
+	switch {
+	case format == 0x83f0 || format == 0x83f1 || format == 0x8c4c || format == 0x8c4d:
+		return "BC1"
+	case format == 0x83f2 || format == 0x8c4e:
+		return "BC2"
+	case format == 0x83f3 || format == 0x8c4f:
+		return "BC3"
+	case format == 0x8dbb || format == 0x8dbc:
+		return "BC4"
+	case format == 0x8dbd || format == 0x8dbe:
+		return "BC5"
+	case format == 0x8e8e || format == 0x8e8f:
+		return "BC6H"
+	case format == 0x8e8c || format == 0x8e8d:
+		return "BC7"
+	case format == 0x8d64:
+		return "ETC1"
+	case format >= 0x9270 && format <= 0x9279:
+		return "ETC2"
+	case format >= 0x93b0 && format <= 0x93dd:
+		return "ASTC"
+	default:
+		return fmt.Sprintf("compressed (0x%04x)", format)
+	}
+}
+
+// vkFormat names the block-compressed Vulkan formats.
+func vkFormat(format uint32) string {
+	switch {
+	case format >= 131 && format <= 134:
+		return "BC1"
+	case format >= 135 && format <= 136:
+		return "BC2"
+	case format >= 137 && format <= 138:
+		return "BC3"
+	case format >= 139 && format <= 140:
+		return "BC4"
+	case format >= 141 && format <= 142:
+		return "BC5"
+	case format >= 143 && format <= 144:
+		return "BC6H"
+	case format >= 145 && format <= 146:
+		return "BC7"
+	case format >= 147 && format <= 156:
+		return "ETC2"
+	case format >= 157 && format <= 184, format >= 1000066000 && format <= 1000066013:
+		return "ASTC"
+	default:
+		return ""
+	}
+}
+
+// textureNotes describes the compression, mipmaps and layers of a texture
+// for verbose output.
+func textureNotes(compression string, mips, layers, depth uint32) []string {
+	var notes []string
+
+	if compression != "" {
+		notes = append(notes, compression)
+	}
+
+	notes = append(notes, fmt.Sprintf("%d mip(s)", max(mips, 1)), fmt.Sprintf("%d layer(s)", max(layers, 1)))
+
+	if depth > 1 {
+		notes = append(notes, fmt.Sprintf("depth %d", depth))
+	}
+
+	return notes
+}
+
+func readTextureHeader(r io.Reader, length int) ([]byte, error) {
+	header := make([]byte, length)
+
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	return header, nil
+}
+
+// ddsDimensions reads a DirectDraw Surface header, including the DX10
+// extension header used for array textures and newer formats.
+func ddsDimensions(r io.ReadSeeker, data *imageData) error {
+	header, err := readTextureHeader(r, ddsHeaderLength)
+	if err != nil {
+		return err
+	}
+
+	if string(header[:4]) != "DDS " || binary.LittleEndian.Uint32(header[4:]) != 124 {
+		return ErrInvalidTexture
+	}
+
+	height := binary.LittleEndian.Uint32(header[12:])
+	width := binary.LittleEndian.Uint32(header[16:])
+	depth := binary.LittleEndian.Uint32(header[24:])
+	mips := binary.LittleEndian.Uint32(header[28:])
+	layers := uint32(1)
+
+	var compression string
+
+	if binary.LittleEndian.Uint32(header[80:])&ddsFourCCFlag != 0 {
+		fourCC := string(header[84:88])
+
+		if fourCC == "DX10" {
+			extension, err := readTextureHeader(r, ddsDX10Length)
+			if err != nil {
+				return err
+			}
+
+			compression = dxgiFormat(binary.LittleEndian.Uint32(extension))
+			layers = max(binary.LittleEndian.Uint32(extension[12:]), 1)
+
+			if binary.LittleEndian.Uint32(extension[8:])&ddsDX10Cubemap != 0 {
+				layers *= 6
+			}
+		} else {
+			compression = ddsFourCCs[fourCC]
+		}
+	}
+
+	if binary.LittleEndian.Uint32(header[112:])&ddsCubemap != 0 && layers == 1 {
+		layers = 6
+	}
+
+	if width == 0 || height == 0 {
+		return ErrInvalidTexture
+	}
+
+	data.width, data.height = int(width), int(height)
+	data.notes = append(data.notes, textureNotes(compression, mips, layers, depth)...)
+
+	return nil
+}
+
+// ktxDimensions reads a KTX 1 header, which may be in either byte order.
+func ktxDimensions(r io.ReadSeeker, data *imageData) error {
+	header, err := readTextureHeader(r, ktxHeaderLength)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.HasPrefix(header, ktxIdentifier) {
+		return ErrInvalidTexture
+	}
+
+	var order binary.ByteOrder = binary.LittleEndian
+	if order.Uint32(header[12:]) != ktxLittleEndian {
+		order = binary.BigEndian
+	}
+
+	width := order.Uint32(header[36:])
+	height := max(order.Uint32(header[40:]), 1)
+	depth := order.Uint32(header[44:])
+	layers := max(order.Uint32(header[48:]), 1) * max(order.Uint32(header[52:]), 1)
+	mips := order.Uint32(header[56:])
+
+	var compression string
+
+	if order.Uint32(header[16:]) == ktxCompressed {
+		compression = glInternalFormat(order.Uint32(header[28:]))
+	}
+
+	if width == 0 {
+		return ErrInvalidTexture
+	}
+
+	data.width, data.height = int(width), int(height)
+	data.notes = append(data.notes, textureNotes(compression, mips, layers, depth)...)
+
+	return nil
+}
+
+// ktx2Dimensions reads a KTX 2 header.
+func ktx2Dimensions(r io.ReadSeeker, data *imageData) error {
+	header, err := readTextureHeader(r, ktx2HeaderLength)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.HasPrefix(header, ktx2Identifier) {
+		return ErrInvalidTexture
+	}
+
+	format := binary.LittleEndian.Uint32(header[12:])
+	width := binary.LittleEndian.Uint32(header[20:])
+	height := max(binary.LittleEndian.Uint32(header[24:]), 1)
+	depth := binary.LittleEndian.Uint32(header[28:])
+	layers := max(binary.LittleEndian.Uint32(header[32:]), 1) * max(binary.LittleEndian.Uint32(header[36:]), 1)
+	mips := binary.LittleEndian.Uint32(header[40:])
+	supercompression := binary.LittleEndian.Uint32(header[44:])
+
+	compression := vkFormat(format)
+
+	switch {
+	case format == ktx2Undefined && supercompression == ktx2BasisLZ:
+		compression = "Basis Universal (ETC1S)"
+	case format == ktx2Undefined:
+		compression = "Basis Universal (UASTC)"
+	}
+
+	switch supercompression {
+	case ktx2Zstandard:
+		compression = strings.TrimSpace(compression + " zstd")
+	case ktx2Zlib:
+		compression = strings.TrimSpace(compression + " zlib")
+	}
+
+	if width == 0 {
+		return ErrInvalidTexture
+	}
+
+	data.width, data.height = int(width), int(height)
+	data.notes = append(data.notes, textureNotes(compression, mips, layers, depth)...)
+
+	return nil
+}
+
+// qoiDimensions reads a Quite OK Image header.
+func qoiDimensions(r io.ReadSeeker, data *imageData) error {
+	header, err := readTextureHeader(r, qoiHeaderLength)
+	if err != nil {
+		return err
+	}
+
+	if string(header[:4]) != "qoif" {
+		return ErrInvalidTexture
+	}
+
+	width := binary.BigEndian.Uint32(header[4:])
+	height := binary.BigEndian.Uint32(header[8:])
+
+	if width == 0 || height == 0 {
+		return ErrInvalidTexture
+	}
+
+	data.width, data.height = int(width), int(height)
+
+	return nil
+}
+
+// isTGA reports whether a file with the given name and leading bytes looks
+// like a Truevision TGA image. With no signature to go on, the file must
+// have a TGA extension and a plausible header.
+func isTGA(name string, header []byte) bool {
+	return tgaExtensions[strings.ToLower(filepath.Ext(name))] && isTGAHeader(header)
+}
+
+// hasTGAFooter reports whether r ends with the footer of a TGA 2.0 file and
+// starts with a plausible header, which identifies a TGA image regardless of
+// its name.
+func hasTGAFooter(r io.ReadSeeker, header []byte) bool {
+	if !isTGAHeader(header) {
+		return false
+	}
+
+	if _, err := r.Seek(-tgaFooterLength, io.SeekEnd); err != nil {
+		return false
+	}
+
+	footer := make([]byte, tgaFooterLength)
+
+	if _, err := io.ReadFull(r, footer); err != nil {
+		return false
+	}
+
+	return bytes.Equal(footer[8:], tgaSignature)
+}
+
+// isTGAHeader reports whether header is a plausible TGA header.
+func isTGAHeader(header []byte) bool {
+	if len(header) < tgaHeaderLength {
+		return false
+	}
+
+	colorMapType, imageType, depth := header[1], header[2], header[16]
+
+	switch {
+	case colorMapType > 1:
+		return false
+	case imageType != 1 && imageType != 2 && imageType != 3 && imageType != 9 && imageType != 10 && imageType != 11:
+		return false
+	case depth != 8 && depth != 15 && depth != 16 && depth != 24 && depth != 32:
+		return false
+	}
+
+	return binary.LittleEndian.Uint16(header[12:]) != 0 && binary.LittleEndian.Uint16(header[14:]) != 0
+}
+
+// tgaDimensions reads a Truevision TGA header.
+func tgaDimensions(r io.ReadSeeker, data *imageData) error {
+	header, err := readTextureHeader(r, tgaHeaderLength)
+	if err != nil {
+		return err
+	}
+
+	data.width = int(binary.LittleEndian.Uint16(header[12:]))
+	data.height = int(binary.LittleEndian.Uint16(header[14:]))
+
+	return nil
+}
+
+// isPowerOfTwo reports whether n is a positive power of two.
+func isPowerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
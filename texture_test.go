@@ -0,0 +1,168 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"slices"
+	"testing"
+)
+
+func testDDS(width, height uint32, fourCC string, extension ...uint32) []byte {
+	header := make([]byte, ddsHeaderLength)
+	copy(header, "DDS ")
+	binary.LittleEndian.PutUint32(header[4:], 124)
+	binary.LittleEndian.PutUint32(header[12:], height)
+	binary.LittleEndian.PutUint32(header[16:], width)
+	binary.LittleEndian.PutUint32(header[28:], 10)
+
+	if fourCC != "" {
+		binary.LittleEndian.PutUint32(header[80:], ddsFourCCFlag)
+		copy(header[84:], fourCC)
+	}
+
+	for _, value := range extension {
+		header = binary.LittleEndian.AppendUint32(header, value)
+	}
+
+	return header
+}
+
+func testKTX(width, height, glFormat uint32) []byte {
+	header := make([]byte, ktxHeaderLength)
+	copy(header, ktxIdentifier)
+	binary.LittleEndian.PutUint32(header[12:], ktxLittleEndian)
+	binary.LittleEndian.PutUint32(header[28:], glFormat)
+	binary.LittleEndian.PutUint32(header[36:], width)
+	binary.LittleEndian.PutUint32(header[40:], height)
+	binary.LittleEndian.PutUint32(header[56:], 1)
+
+	return header
+}
+
+func testKTX2(width, height, vkFormat, supercompression uint32) []byte {
+	header := make([]byte, ktx2HeaderLength)
+	copy(header, ktx2Identifier)
+	binary.LittleEndian.PutUint32(header[12:], vkFormat)
+	binary.LittleEndian.PutUint32(header[20:], width)
+	binary.LittleEndian.PutUint32(header[24:], height)
+	binary.LittleEndian.PutUint32(header[36:], 6)
+	binary.LittleEndian.PutUint32(header[40:], 1)
+	binary.LittleEndian.PutUint32(header[44:], supercompression)
+
+	return header
+}
+
+func testQOI(width, height uint32) []byte {
+	header := []byte("qoif")
+	header = binary.BigEndian.AppendUint32(header, width)
+	header = binary.BigEndian.AppendUint32(header, height)
+
+	return append(header, 4, 0)
+}
+
+// testTGA assembles an uncompressed true-colour TGA file, optionally ending
+// with a TGA 2.0 footer.
+func testTGA(width, height uint16, footer bool) []byte {
+	header := make([]byte, tgaHeaderLength)
+	header[2] = 2
+	binary.LittleEndian.PutUint16(header[12:], width)
+	binary.LittleEndian.PutUint16(header[14:], height)
+	header[16] = 32
+
+	header = append(header, make([]byte, 16)...)
+
+	if footer {
+		header = append(append(header, make([]byte, 8)...), tgaSignature...)
+	}
+
+	return header
+}
+
+func TestTextureDimensions(t *testing.T) {
+	tests := []struct {
+		name    string
+		decoder func(io.ReadSeeker, *imageData) error
+		input   []byte
+		width   int
+		height  int
+		notes   []string
+		err     bool
+	}{
+		{"dds", ddsDimensions, testDDS(256, 128, "DXT5"), 256, 128, []string{"BC3", "10 mip(s)", "1 layer(s)"}, false},
+		{"dds dx10 cubemap", ddsDimensions, testDDS(64, 64, "DX10", 98, 3, ddsDX10Cubemap, 2, 0), 64, 64, []string{"BC7", "10 mip(s)", "12 layer(s)"}, false},
+		{"dds truncated", ddsDimensions, testDDS(256, 128, "")[:100], 0, 0, nil, true},
+		{"dds truncated extension", ddsDimensions, testDDS(64, 64, "DX10", 98), 0, 0, nil, true},
+		{"ktx", ktxDimensions, testKTX(512, 256, 0x8e8c), 512, 256, []string{"BC7", "1 mip(s)", "1 layer(s)"}, false},
+		{"ktx truncated", ktxDimensions, testKTX(512, 256, 0)[:40], 0, 0, nil, true},
+		{"ktx2", ktx2Dimensions, testKTX2(1024, 1024, 0, ktx2BasisLZ), 1024, 1024, []string{"Basis Universal (ETC1S)", "1 mip(s)", "6 layer(s)"}, false},
+		{"ktx2 zstd", ktx2Dimensions, testKTX2(100, 50, 145, ktx2Zstandard), 100, 50, []string{"BC7 zstd", "1 mip(s)", "6 layer(s)"}, false},
+		{"ktx2 truncated", ktx2Dimensions, testKTX2(1024, 1024, 0, 0)[:20], 0, 0, nil, true},
+		{"qoi", qoiDimensions, testQOI(800, 600), 800, 600, nil, false},
+		{"qoi zero height", qoiDimensions, testQOI(800, 0), 0, 0, nil, true},
+		{"qoi truncated", qoiDimensions, testQOI(800, 600)[:8], 0, 0, nil, true},
+		{"tga", tgaDimensions, testTGA(300, 200, false), 300, 200, nil, false},
+		{"tga truncated", tgaDimensions, testTGA(300, 200, false)[:10], 0, 0, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var data imageData
+
+			err := test.decoder(bytes.NewReader(test.input), &data)
+			if test.err {
+				if err == nil {
+					t.Errorf("got %dx%d, want error", data.width, data.height)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if data.width != test.width || data.height != test.height || !slices.Equal(data.notes, test.notes) {
+				t.Errorf("got %dx%d %q, want %dx%d %q", data.width, data.height, data.notes, test.width, test.height, test.notes)
+			}
+		})
+	}
+}
+
+func TestTGADetection(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		input []byte
+		found bool
+	}{
+		{"extension", "image.tga", testTGA(300, 200, false), true},
+		{"footer", "image.bin", testTGA(300, 200, true), true},
+		{"footer without extension or name", "", testTGA(300, 200, true), true},
+		{"no footer or extension", "image.bin", testTGA(300, 200, false), false},
+		{"implausible header", "image.tga", make([]byte, 64), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := imageData{name: test.file}
+
+			found, err := decodeDimensions(bytes.NewReader(test.input), &data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if found != test.found {
+				t.Fatalf("got found %v, want %v", found, test.found)
+			}
+
+			if found && (data.format != "tga" || data.width != 300 || data.height != 200) {
+				t.Errorf("got %s %dx%d, want tga 300x200", data.format, data.width, data.height)
+			}
+		})
+	}
+}
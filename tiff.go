@@ -0,0 +1,332 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+)
+
+var (
+	ErrInvalidTIFF = errors.New("invalid tiff structure")
+)
+
+const (
+	tiffNewSubfileType = 254
+	tiffImageWidth     = 256
+	tiffImageLength    = 257
+
+	// maxIFDs bounds the number of IFDs followed in a single file, guarding
+	// against offset loops in malformed files.
+	maxIFDs = 4096
+
+	// maxTIFFEntries bounds the number of entries read from a single IFD.
+	maxTIFFEntries = 4096
+)
+
+var tiffTypeSizes = map[uint16]int{
+	1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8, 13: 4, 16: 8, 17: 8, 18: 8,
+}
+
+type tiffEntry struct {
+	kind  uint16
+	count uint64
+	value []byte
+}
+
+type ifd struct {
+	offset  int64
+	entries map[uint16]tiffEntry
+	next    int64
+}
+
+// tiffReader reads IFDs from classic TIFF and BigTIFF files, as well as the
+// TIFF-based structures used by EXIF and camera RAW formats.
+type tiffReader struct {
+	r     io.ReadSeeker
+	order binary.ByteOrder
+	big   bool
+	first int64
+}
+
+// newTIFFReader parses a TIFF header at the start of r. The magic number is
+// not checked, as several RAW formats substitute their own.
+func newTIFFReader(r io.ReadSeeker) (*tiffReader, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var header [16]byte
+
+	if _, err := io.ReadFull(r, header[:8]); err != nil {
+		return nil, err
+	}
+
+	t := &tiffReader{r: r}
+
+	switch string(header[:2]) {
+	case "II":
+		t.order = binary.LittleEndian
+	case "MM":
+		t.order = binary.BigEndian
+	default:
+		return nil, ErrInvalidTIFF
+	}
+
+	if t.order.Uint16(header[2:]) == 43 {
+		if _, err := io.ReadFull(r, header[8:16]); err != nil {
+			return nil, err
+		}
+
+		if t.order.Uint16(header[4:]) != 8 {
+			return nil, ErrInvalidTIFF
+		}
+
+		t.big = true
+		t.first = int64(t.order.Uint64(header[8:]))
+	} else {
+		t.first = int64(t.order.Uint32(header[4:]))
+	}
+
+	if t.first < 8 {
+		return nil, ErrInvalidTIFF
+	}
+
+	return t, nil
+}
+
+func (t *tiffReader) readAt(offset int64, length int) ([]byte, error) {
+	if _, err := t.r.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, length)
+
+	if _, err := io.ReadFull(t.r, buf); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// readIFD reads the directory at offset, loading each entry's value.
+func (t *tiffReader) readIFD(offset int64) (*ifd, error) {
+	countSize, entrySize, offsetSize := 2, 12, 4
+	if t.big {
+		countSize, entrySize, offsetSize = 8, 20, 8
+	}
+
+	raw, err := t.readAt(offset, countSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var count uint64
+	if t.big {
+		count = t.order.Uint64(raw)
+	} else {
+		count = uint64(t.order.Uint16(raw))
+	}
+
+	if count > maxTIFFEntries {
+		return nil, ErrInvalidTIFF
+	}
+
+	raw, err = t.readAt(offset+int64(countSize), int(count)*entrySize+offsetSize)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := &ifd{offset: offset, entries: make(map[uint16]tiffEntry, count)}
+
+	for i := range int(count) {
+		entry := raw[i*entrySize : (i+1)*entrySize]
+
+		tag := t.order.Uint16(entry)
+		kind := t.order.Uint16(entry[2:])
+
+		var n uint64
+		var inline []byte
+
+		if t.big {
+			n, inline = t.order.Uint64(entry[4:]), entry[12:20]
+		} else {
+			n, inline = uint64(t.order.Uint32(entry[4:])), entry[8:12]
+		}
+
+		size, ok := tiffTypeSizes[kind]
+		if !ok || n > 1<<20 {
+			continue
+		}
+
+		length := int(n) * size
+
+		value := inline[:min(length, len(inline))]
+
+		if length > len(inline) {
+			var valueOffset int64
+			if t.big {
+				valueOffset = int64(t.order.Uint64(inline))
+			} else {
+				valueOffset = int64(t.order.Uint32(inline))
+			}
+
+			value, err = t.readAt(valueOffset, length)
+			if err != nil {
+				continue
+			}
+		}
+
+		dir.entries[tag] = tiffEntry{kind: kind, count: n, value: value}
+	}
+
+	next := raw[int(count)*entrySize:]
+	if t.big {
+		dir.next = int64(t.order.Uint64(next))
+	} else {
+		dir.next = int64(t.order.Uint32(next))
+	}
+
+	return dir, nil
+}
+
+// uint returns the i-th value of an integer entry.
+func (t *tiffReader) uint(e tiffEntry, i int) (uint64, bool) {
+	if uint64(i) >= e.count {
+		return 0, false
+	}
+
+	switch e.kind {
+	case 1, 6, 7:
+		return uint64(e.value[i]), true
+	case 3, 8:
+		return uint64(t.order.Uint16(e.value[i*2:])), true
+	case 4, 9, 13:
+		return uint64(t.order.Uint32(e.value[i*4:])), true
+	case 16, 17, 18:
+		return t.order.Uint64(e.value[i*8:]), true
+	default:
+		return 0, false
+	}
+}
+
+// number returns the i-th value of an integer or rational entry.
+func (t *tiffReader) number(e tiffEntry, i int) (float64, bool) {
+	if e.kind != 5 {
+		value, ok := t.uint(e, i)
+
+		return float64(value), ok
+	}
+
+	if uint64(i) >= e.count {
+		return 0, false
+	}
+
+	numerator := t.order.Uint32(e.value[i*8:])
+	denominator := t.order.Uint32(e.value[i*8+4:])
+
+	if denominator == 0 {
+		return 0, false
+	}
+
+	return float64(numerator) / float64(denominator), true
+}
+
+// tag returns the first value of an integer tag in dir.
+func (t *tiffReader) tag(dir *ifd, tag uint16) (uint64, bool) {
+	e, ok := dir.entries[tag]
+	if !ok {
+		return 0, false
+	}
+
+	return t.uint(e, 0)
+}
+
+// chain reads the linked list of IFDs starting at offset.
+func (t *tiffReader) chain(offset int64) ([]*ifd, error) {
+	var dirs []*ifd
+
+	seen := make(map[int64]bool)
+
+	for offset != 0 && !seen[offset] && len(dirs) < maxIFDs {
+		seen[offset] = true
+
+		dir, err := t.readIFD(offset)
+		if err != nil {
+			if len(dirs) > 0 {
+				break
+			}
+
+			return nil, err
+		}
+
+		dirs = append(dirs, dir)
+		offset = dir.next
+	}
+
+	return dirs, nil
+}
+
+// tiffDimensions reads the dimensions of each page of a TIFF or BigTIFF
+// file, skipping reduced-resolution thumbnails. The first page supplies the
+// dimensions of the file itself, unless --pages is set, in which case each
+// page is reported separately.
+func tiffDimensions(r io.ReadSeeker, data *imageData) error {
+	t, err := newTIFFReader(r)
+	if err != nil {
+		return err
+	}
+
+	dirs, err := t.chain(t.first)
+	if err != nil {
+		return err
+	}
+
+	var pages []subimage
+
+	for _, dir := range dirs {
+		if subfileType, ok := t.tag(dir, tiffNewSubfileType); ok && subfileType&1 != 0 {
+			continue
+		}
+
+		width, ok := t.tag(dir, tiffImageWidth)
+		if !ok {
+			continue
+		}
+
+		height, ok := t.tag(dir, tiffImageLength)
+		if !ok {
+			continue
+		}
+
+		page := subimage{
+			suffix: strconv.Itoa(len(pages) + 1),
+			width:  int(width),
+			height: int(height),
+		}
+
+		if value, ok := t.tag(dir, exifOrientationTag); ok && value >= 1 && value <= 8 {
+			page.orientation = int(value)
+		} else {
+			page.orientation = 1
+		}
+
+		pages = append(pages, page)
+	}
+
+	if len(pages) == 0 {
+		return ErrInvalidTIFF
+	}
+
+	data.width, data.height, data.orientation = pages[0].width, pages[0].height, pages[0].orientation
+
+	if allPages && len(pages) > 1 {
+		data.subimages = pages
+	}
+
+	return nil
+}
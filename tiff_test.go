@@ -0,0 +1,146 @@
+/*
+Copyright © 2026 Seednode <seednode@seedno.de>
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+type testTIFFEntry struct {
+	tag   uint16
+	kind  uint16
+	count uint32
+	value []byte
+}
+
+func testShort(tag, value uint16) testTIFFEntry {
+	return testTIFFEntry{tag, 3, 1, binary.LittleEndian.AppendUint16(nil, value)}
+}
+
+func testLong(tag uint16, value uint32) testTIFFEntry {
+	return testTIFFEntry{tag, 4, 1, binary.LittleEndian.AppendUint32(nil, value)}
+}
+
+func testASCII(tag uint16, value string) testTIFFEntry {
+	return testTIFFEntry{tag, 2, uint32(len(value) + 1), append([]byte(value), 0)}
+}
+
+// testTIFF assembles a little-endian classic TIFF file from a chain of IFDs,
+// storing values that do not fit in an entry after the last IFD.
+func testTIFF(ifds ...[]testTIFFEntry) []byte {
+	offset := 8
+	for _, entries := range ifds {
+		offset += 2 + 12*len(entries) + 4
+	}
+
+	out := []byte("II\x2a\x00\x08\x00\x00\x00")
+
+	var extra []byte
+
+	for i, entries := range ifds {
+		out = binary.LittleEndian.AppendUint16(out, uint16(len(entries)))
+
+		for _, entry := range entries {
+			out = binary.LittleEndian.AppendUint16(out, entry.tag)
+			out = binary.LittleEndian.AppendUint16(out, entry.kind)
+			out = binary.LittleEndian.AppendUint32(out, entry.count)
+
+			if len(entry.value) > 4 {
+				out = binary.LittleEndian.AppendUint32(out, uint32(offset+len(extra)))
+				extra = append(extra, entry.value...)
+			} else {
+				out = append(out, entry.value...)
+				out = append(out, make([]byte, 4-len(entry.value))...)
+			}
+		}
+
+		next := 0
+		if i < len(ifds)-1 {
+			next = len(out) + 4
+		}
+
+		out = binary.LittleEndian.AppendUint32(out, uint32(next))
+	}
+
+	return append(out, extra...)
+}
+
+func TestTiffDimensions(t *testing.T) {
+	page := func(width, height uint32) []testTIFFEntry {
+		return []testTIFFEntry{testLong(tiffImageWidth, width), testLong(tiffImageLength, height)}
+	}
+
+	thumbnail := append(page(160, 120), testLong(tiffNewSubfileType, 1))
+	rotated := append(page(4000, 3000), testShort(exifOrientationTag, 6))
+
+	bigTIFF := []byte("II\x2b\x00\x08\x00\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00")
+	bigTIFF = binary.LittleEndian.AppendUint64(bigTIFF, 2)
+	for _, entry := range [][2]uint16{{tiffImageWidth, 1920}, {tiffImageLength, 1080}} {
+		bigTIFF = binary.LittleEndian.AppendUint16(bigTIFF, entry[0])
+		bigTIFF = binary.LittleEndian.AppendUint16(bigTIFF, 3)
+		bigTIFF = binary.LittleEndian.AppendUint64(bigTIFF, 1)
+		bigTIFF = binary.LittleEndian.AppendUint64(bigTIFF, uint64(entry[1]))
+	}
+	bigTIFF = binary.LittleEndian.AppendUint64(bigTIFF, 0)
+
+	single := testTIFF(page(640, 480))
+
+	tests := []struct {
+		name        string
+		input       []byte
+		width       int
+		height      int
+		orientation int
+		split       bool
+		pages       int
+		err         bool
+	}{
+		{"single page", single, 640, 480, 1, false, 0, false},
+		{"thumbnail skipped", testTIFF(thumbnail, page(640, 480)), 640, 480, 1, false, 0, false},
+		{"multiple pages", testTIFF(page(640, 480), thumbnail, page(320, 240)), 640, 480, 1, true, 2, false},
+		{"multiple pages merged", testTIFF(page(640, 480), thumbnail, page(320, 240)), 640, 480, 1, false, 0, false},
+		{"orientation", testTIFF(rotated), 4000, 3000, 6, false, 0, false},
+		{"bigtiff", bigTIFF, 1920, 1080, 1, false, 0, false},
+		{"truncated header", single[:6], 0, 0, 0, false, 0, true},
+		{"truncated ifd", single[:20], 0, 0, 0, false, 0, true},
+		{"no dimensions", testTIFF([]testTIFFEntry{testShort(exifOrientationTag, 1)}), 0, 0, 0, false, 0, true},
+		{"bad byte order", []byte("XX\x2a\x00\x08\x00\x00\x00"), 0, 0, 0, false, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			saved := allPages
+			t.Cleanup(func() { allPages = saved })
+
+			allPages = test.split
+
+			var data imageData
+
+			err := tiffDimensions(bytes.NewReader(test.input), &data)
+			if test.err {
+				if err == nil {
+					t.Errorf("got %dx%d, want error", data.width, data.height)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if data.width != test.width || data.height != test.height || data.orientation != test.orientation {
+				t.Errorf("got %dx%d with orientation %d, want %dx%d with orientation %d",
+					data.width, data.height, data.orientation, test.width, test.height, test.orientation)
+			}
+
+			if len(data.subimages) != test.pages {
+				t.Errorf("got %d pages, want %d", len(data.subimages), test.pages)
+			}
+		})
+	}
+}
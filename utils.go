@@ -7,27 +7,25 @@ package main
 import (
 	"errors"
 	"fmt"
-	"image"
-	_ "image/gif"
-	_ "image/jpeg"
-	_ "image/png"
 	"io"
 	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
-	avif "github.com/gen2brain/avif"
-	heic "github.com/gen2brain/heic"
-	jpegxl "github.com/gen2brain/jpegxl"
+	"github.com/spf13/cobra"
+)
 
-	_ "golang.org/x/image/bmp"
-	_ "golang.org/x/image/vp8l"
-	_ "golang.org/x/image/webp"
+var (
+	ErrInvalidOrientation = errors.New("orientation must be one of portrait, landscape, or square")
+	ErrInvalidRange       = errors.New("minimum must not be greater than maximum")
+	ErrNoFilter           = errors.New("no filter specified")
 )
 
 type sortDirection int
@@ -43,67 +41,170 @@ const (
 	name sortKey = iota
 	height
 	width
+	ratio
+	area
+	format
 )
 
-type compareType int
-
-const (
-	wider compareType = iota
-	narrower
-	taller
-	shorter
-)
-
-type comparison struct {
-	operator compareType
-	value    int
+// A subimage is one of several images stored in a single file, such as the
+// pages of a multi-page TIFF. Decoders only populate subimages when they are
+// to be reported separately.
+type subimage struct {
+	suffix      string
+	width       int
+	height      int
+	orientation int
 }
 
 type imageData struct {
-	name   string
-	width  int
-	height int
+	name        string
+	width       int
+	height      int
+	format      string
+	size        int64
+	mtime       time.Time
+	orientation int
+	subimages   []subimage
+	notes       []string
+
+	// target is the path a file resolves to when it was reached through a
+	// symlink with --follow.
+	target string
 }
 
-func imageDimensions(path string) (w, h int, ok bool, err error) {
+func imageDimensions(path string) ([]imageData, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return 0, 0, false, nil
+			return nil, nil
 		}
-		return 0, 0, false, err
+		return nil, err
 	}
 	defer f.Close()
 
-	cfg, _, err := image.DecodeConfig(f)
-	if err == nil {
-		return cfg.Width, cfg.Height, true, nil
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return readImages(f, imageData{
+		name:  path,
+		size:  info.Size(),
+		mtime: info.ModTime(),
+	}, 0)
+}
+
+// readImages reads the dimensions of the image in r, or with --archives or
+// --documents, of every image in the archive or document in r. The depth is
+// the number of archives and documents which enclose r.
+func readImages(r io.ReadSeeker, data imageData, depth int) ([]imageData, error) {
+	ok, err := decodeDimensions(r, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		if documents && depth < maxArchiveDepth {
+			if kind := documentKind(r); kind != "" {
+				return documentImages(r, data, kind, depth)
+			}
+		}
+
+		if archives {
+			return archiveImages(r, data, depth)
+		}
+
+		return nil, nil
+	}
+
+	// Some containers record their orientation alongside the dimensions.
+	if exifOrient && data.orientation == 0 {
+		data.orientation = orientation(r, data.format)
 	}
 
-	if errors.Is(err, image.ErrFormat) {
-		if _, seekErr := f.Seek(0, io.SeekStart); seekErr != nil {
-			return 0, 0, false, seekErr
+	images := []imageData{data}
+
+	if len(data.subimages) > 0 {
+		images = splitSubimages(data)
+	}
+
+	if exifOrient {
+		for i := range images {
+			if swapsAxes(images[i].orientation) {
+				images[i].width, images[i].height = images[i].height, images[i].width
+			}
 		}
+	}
+
+	return images, nil
+}
+
+// splitSubimages returns an entry for each subimage of a file, named after
+// the file with the subimage's suffix appended (e.g. scan.tif#2).
+func splitSubimages(data imageData) []imageData {
+	images := make([]imageData, 0, len(data.subimages))
 
-		jxlCfg, err := jpegxl.DecodeConfig(f)
-		if err == nil {
-			return jxlCfg.Width, jxlCfg.Height, true, nil
+	for _, sub := range data.subimages {
+		image := data
+		image.name = data.name + "#" + sub.suffix
+		image.width, image.height = sub.width, sub.height
+		image.subimages = nil
+
+		if sub.orientation != 0 {
+			image.orientation = sub.orientation
 		}
 
-		avifCfg, err := avif.DecodeConfig(f)
-		if err == nil {
-			return avifCfg.Width, avifCfg.Height, true, nil
+		images = append(images, image)
+	}
+
+	return images
+}
+
+// decodeDimensions identifies the format of f from its leading bytes and
+// reads its dimensions with the matching decoder, so that files which are not
+// images are rejected without invoking any decoder.
+func decodeDimensions(f io.ReadSeeker, data *imageData) (bool, error) {
+	header, err := readHeader(f)
+	if err != nil {
+		return false, err
+	}
+
+	detected := sniff(data.name, header)
+
+	switch {
+	case detected == "tiff":
+		if raw := sniffRaw(f, data.name); raw != "" {
+			detected = raw
 		}
+	case detected == "" && hasTGAFooter(f, header):
+		detected = "tga"
+	}
+
+	if detected == "" {
+		return false, nil
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
 
-		heicCfg, err := heic.DecodeConfig(f)
-		if err == nil {
-			return heicCfg.Width, heicCfg.Height, true, nil
+	// Files which look like images but fail to decode are skipped rather than
+	// aborting the scan, as the fallback decoders have always done.
+	err = decoders[detected](f, data)
+	switch {
+	case errors.Is(err, ErrNoSize):
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Skipping %s: %v.\n", data.name, err)
 		}
 
-		return 0, 0, false, nil
+		return false, nil
+	case err != nil:
+		return false, nil
 	}
 
-	return 0, 0, false, err
+	data.format = detected
+
+	return true, nil
 }
 
 func parseSortBy() sortKey {
@@ -114,6 +215,12 @@ func parseSortBy() sortKey {
 		return height
 	case key == "width":
 		return width
+	case key == "ratio":
+		return ratio
+	case key == "area":
+		return area
+	case key == "format":
+		return format
 	default:
 		fmt.Println(`Unknown key provided. Defaulting to "name".`)
 
@@ -134,38 +241,134 @@ func parseSortOrder() sortDirection {
 	}
 }
 
+func pixelCount(image imageData) int64 {
+	return int64(image.width) * int64(image.height)
+}
+
+func aspectRatio(image imageData) float64 {
+	if image.height == 0 {
+		return 0
+	}
+
+	return float64(image.width) / float64(image.height)
+}
+
 func sortOutput(outputs []imageData) {
 	sortBy, sortOrder := parseSortBy(), parseSortOrder()
 
-	switch {
-	case sortOrder == ascending && sortBy == height:
-		sort.SliceStable(outputs, func(p, q int) bool {
-			return outputs[p].height < outputs[q].height
-		})
-	case sortOrder == ascending && sortBy == width:
-		sort.SliceStable(outputs, func(p, q int) bool {
-			return outputs[p].width < outputs[q].width
-		})
-	case sortOrder == ascending && sortBy == name:
-		sort.SliceStable(outputs, func(p, q int) bool {
-			return outputs[p].name < outputs[q].name
-		})
-	case sortOrder == descending && sortBy == height:
-		sort.SliceStable(outputs, func(p, q int) bool {
-			return outputs[p].height > outputs[q].height
-		})
-	case sortOrder == descending && sortBy == width:
-		sort.SliceStable(outputs, func(p, q int) bool {
-			return outputs[p].width > outputs[q].width
-		})
-	case sortOrder == descending && sortBy == name:
-		sort.SliceStable(outputs, func(p, q int) bool {
-			return outputs[p].name > outputs[q].name
-		})
+	var less func(p, q imageData) bool
+
+	switch sortBy {
+	case height:
+		less = func(p, q imageData) bool {
+			return p.height < q.height
+		}
+	case width:
+		less = func(p, q imageData) bool {
+			return p.width < q.width
+		}
+	case ratio:
+		less = func(p, q imageData) bool {
+			return aspectRatio(p) < aspectRatio(q)
+		}
+	case area:
+		less = func(p, q imageData) bool {
+			return pixelCount(p) < pixelCount(q)
+		}
+	case format:
+		less = func(p, q imageData) bool {
+			return p.format < q.format
+		}
+	default:
+		less = func(p, q imageData) bool {
+			return p.name < q.name
+		}
+	}
+
+	sort.SliceStable(outputs, func(p, q int) bool {
+		if sortOrder == descending {
+			return less(outputs[q], outputs[p])
+		}
+
+		return less(outputs[p], outputs[q])
+	})
+}
+
+// scanFile sends each image in the file at path which matches filter to
+// results.
+func scanFile(path string, filter expression, scans chan int, results chan<- imageData) error {
+	scans <- 1
+
+	defer func() {
+		<-scans
+	}()
+
+	images, err := imageDimensions(path)
+	if err != nil {
+		return err
+	}
+
+	var target string
+
+	if follow {
+		target = linkTarget(path)
+	}
+
+	for _, image := range images {
+		image.target = target
+
+		if filter.matches(&image) {
+			results <- image
+		}
+	}
+
+	return nil
+}
+
+// scanPath scans path as a directory if it is one, or as a single file
+// otherwise.
+func scanPath(path string, filter expression, scans chan int, results chan<- imageData) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		var ancestors *dirChain
+
+		if follow {
+			ancestors, _ = ancestors.enter(path)
+		}
+
+		var ignores *ignoreList
+
+		if ignoring() {
+			var ignored bool
+
+			ignores, ignored = parentIgnores(path)
+			if ignored {
+				return nil
+			}
+		}
+
+		return walkPath(path, path, ancestors, ignores, filter, scans, results)
 	}
+
+	// Files passed directly are at depth 0.
+	if !withinDepth(0) {
+		return nil
+	}
+
+	return scanFile(path, filter, scans, results)
 }
 
-func walkPath(path string, compare *comparison, scans chan int, results chan<- imageData) error {
+// walkPath scans the entries of the directory at path, which is root or one
+// of its subdirectories. Entries excluded by --include, --exclude,
+// --exclude-dir or ignore files, or outside of --min-depth and --max-depth,
+// are skipped without being opened. The directories from root down to path
+// are passed in ancestors when following symlinks, and the ignore rules of
+// the directories above path in ignores.
+func walkPath(root, path string, ancestors *dirChain, ignores *ignoreList, filter expression, scans chan int, results chan<- imageData) error {
 	scans <- 1
 
 	defer func() {
@@ -180,6 +383,10 @@ func walkPath(path string, compare *comparison, scans chan int, results chan<- i
 		return err
 	}
 
+	if ignoring() {
+		ignores = ignores.load(path)
+	}
+
 	var wg sync.WaitGroup
 
 	for _, node := range nodes {
@@ -189,39 +396,56 @@ func walkPath(path string, compare *comparison, scans chan int, results chan<- i
 			defer wg.Done()
 
 			fullPath := filepath.Join(path, node.Name())
+			rel := relativePath(root, fullPath)
 
-			switch {
-			case node.IsDir() && recursive:
-				err := walkPath(fullPath, compare, scans, results)
-				if err != nil {
-					errs <- err
+			depth := entryDepth(rel)
 
+			isDir := node.IsDir()
+
+			// Symlinks to directories are only followed with --follow, and
+			// dangling links are skipped.
+			if node.Type()&fs.ModeSymlink != 0 {
+				info, err := os.Stat(fullPath)
+				if err != nil || info.IsDir() && !follow {
 					return
 				}
-			case !node.IsDir():
-				scans <- 1
 
-				defer func() {
-					<-scans
-				}()
+				isDir = info.IsDir()
+			}
+
+			if ignoring() && ignores.ignored(fullPath, isDir) {
+				return
+			}
+
+			switch {
+			case isDir && recursive && descendsTo(depth) && !skipDir(rel):
+				descendants := ancestors
+
+				if follow {
+					var ok bool
 
-				width, height, ok, err := imageDimensions(fullPath)
-				if err != nil || !ok {
+					descendants, ok = ancestors.enter(fullPath)
+					if !ok {
+						if verbose {
+							fmt.Fprintf(os.Stderr, "Skipping %s: file system loop detected.\n", fullPath)
+						}
+
+						return
+					}
+				}
+
+				err := walkPath(root, fullPath, descendants, ignores, filter, scans, results)
+				if err != nil {
 					errs <- err
 
 					return
 				}
+			case !isDir && withinDepth(depth) && !skipFile(rel):
+				err := scanFile(fullPath, filter, scans, results)
+				if err != nil {
+					errs <- err
 
-				switch {
-				case orEqual && compare.operator == wider && width >= compare.value,
-					orEqual && compare.operator == narrower && width <= compare.value,
-					orEqual && compare.operator == taller && height >= compare.value,
-					orEqual && compare.operator == shorter && height <= compare.value,
-					compare.operator == wider && width > compare.value,
-					compare.operator == narrower && width < compare.value,
-					compare.operator == taller && height > compare.value,
-					compare.operator == shorter && height < compare.value:
-					results <- imageData{name: fullPath, width: width, height: height}
+					return
 				}
 			}
 		}(node)
@@ -246,27 +470,249 @@ Poll:
 	return nil
 }
 
-func imageSizes(compareOperator compareType, arguments []string) error {
+// parseThreshold parses a subcommand argument for the given field. Areas
+// accept megapixel suffixes such as 12MP; other dimensions are whole pixels.
+func parseThreshold(f field, argument string) (float64, error) {
+	if f == areaField {
+		return parsePixels(argument)
+	}
+
+	threshold, err := strconv.Atoi(argument)
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(threshold), nil
+}
+
+// thresholdFilter builds the comparison used by the over and under
+// subcommands, widening it to include the threshold when --or-equal is set.
+func thresholdFilter(f field, operator compareType, argument string) (expression, error) {
+	threshold, err := parseThreshold(f, argument)
+	if err != nil {
+		return nil, err
+	}
+
+	if orEqual {
+		operator = operator.orEqual()
+	}
+
+	return newComparison(f, operator, threshold), nil
+}
+
+// rangeFilter builds the pair of comparisons used by the between subcommands.
+// Both bounds are inclusive unless --exclusive-min or --exclusive-max is set.
+func rangeFilter(f field, minimum, maximum string) (expression, error) {
+	lower, err := parseThreshold(f, minimum)
+	if err != nil {
+		return nil, err
+	}
+
+	upper, err := parseThreshold(f, maximum)
+	if err != nil {
+		return nil, err
+	}
+
+	if lower > upper {
+		return nil, ErrInvalidRange
+	}
+
+	lowerOperator, upperOperator := greaterOrEqual, lessOrEqual
+
+	if exclusiveMin {
+		lowerOperator = greater
+	}
+
+	if exclusiveMax {
+		upperOperator = less
+	}
+
+	return &andExpression{
+		left:  newComparison(f, lowerOperator, lower),
+		right: newComparison(f, upperOperator, upper),
+	}, nil
+}
+
+// formatAliases maps alternate names for formats onto the names reported by
+// the decoders.
+var formatAliases = map[string]string{
+	"jpg":  "jpeg",
+	"heif": "heic",
+	"j2c":  "j2k",
+	"jpc":  "j2k",
+	"jpf":  "jpx",
+	"tif":  "tiff",
+}
+
+func normalizeFormat(f string) string {
+	f = strings.ToLower(f)
+
+	if alias, ok := formatAliases[f]; ok {
+		return alias
+	}
+
+	return f
+}
+
+// formatFilter builds a filter matching any of the specified formats.
+func formatFilter(formats []string) expression {
+	var filter expression
+
+	for _, f := range formats {
+		f = normalizeFormat(strings.TrimSpace(f))
+		if f == "" {
+			continue
+		}
+
+		c := &comparison{left: formatField, operator: equal, right: stringLiteral(f)}
+
+		if filter == nil {
+			filter = c
+		} else {
+			filter = &orExpression{left: filter, right: c}
+		}
+	}
+
+	return filter
+}
+
+// orientationFilter builds the comparison used by the orientation subcommand.
+func orientationFilter(argument string) (expression, error) {
+	switch argument {
+	case "portrait":
+		return &comparison{left: heightField, operator: greater, right: widthField}, nil
+	case "landscape":
+		return &comparison{left: widthField, operator: greater, right: heightField}, nil
+	case "square":
+		return &comparison{left: widthField, operator: equal, right: heightField}, nil
+	default:
+		return nil, ErrInvalidOrientation
+	}
+}
+
+// ratioFilter builds the comparison used by the ratio subcommands. Equality
+// checks honour --tolerance, while over and under honour --or-equal.
+func ratioFilter(operator compareType, argument string) (expression, error) {
+	target, err := parseRatio(argument)
+	if err != nil {
+		return nil, err
+	}
+
+	if orEqual {
+		operator = operator.orEqual()
+	}
+
+	c := &comparison{
+		left:     ratioField,
+		operator: operator,
+		right:    numberLiteral(target),
+	}
+
+	if operator == equal || operator == notEqual {
+		c.tolerance = tolerance
+	}
+
+	return c, nil
+}
+
+// describe returns the dimensions, area, format and any notes for an image,
+// as shown in verbose output.
+func describe(image imageData) string {
+	details := append([]string{
+		fmt.Sprintf("%vx%v", image.width, image.height),
+		fmt.Sprintf("%.2f MP", float64(pixelCount(image))/1e6),
+		image.format,
+	}, image.notes...)
+
+	return strings.Join(details, ", ")
+}
+
+func imageSizes(cmd *cobra.Command, filter expression, paths []string) error {
 	log.SetFlags(0)
 
 	startTime := time.Now()
 
-	if len(arguments) == 1 {
-		arguments = append(arguments, ".")
+	if where != "" {
+		whereFilter, err := parseExpression(where)
+		if err != nil {
+			return err
+		}
+
+		filter = combine(filter, whereFilter)
+	}
 
-		fmt.Println("No path specified. Defaulting to current directory.")
+	if len(formats) > 0 {
+		filter = combine(filter, formatFilter(formats))
 	}
 
-	compareValue, err := strconv.Atoi(arguments[0])
-	if err != nil {
+	if len(notFormats) > 0 {
+		if excluded := formatFilter(notFormats); excluded != nil {
+			filter = combine(filter, &notExpression{operand: excluded})
+		}
+	}
+
+	if nonPowerOfTwo {
+		filter = combine(filter, &nonPowerOfTwoExpression{})
+	}
+
+	if filter == nil {
+		return ErrNoFilter
+	}
+
+	if !validIconEntry() {
+		return ErrInvalidIconEntry
+	}
+
+	if !validRawDimensions() {
+		return ErrInvalidRawDimensions
+	}
+
+	if err := validPatterns(); err != nil {
 		return err
 	}
 
-	compare := &comparison{
-		operator: compareOperator,
-		value:    compareValue,
+	if minDepth < 0 || maxDepth < -1 {
+		return ErrInvalidDepth
+	}
+
+	// Depth limits only make sense when descending into subdirectories.
+	if maxDepth >= 0 || minDepth > 0 {
+		recursive = true
+	}
+
+	readStdin := slices.Contains(paths, stdinPath)
+
+	if readStdin {
+		paths = slices.DeleteFunc(paths, func(path string) bool {
+			return path == stdinPath
+		})
+
+		if filesFrom == stdinPath {
+			return ErrStdinInUse
+		}
+	}
+
+	var listed []string
+
+	if filesFrom != "" {
+		var err error
+
+		listed, err = readFileList(filesFrom)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(paths) == 0 && filesFrom == "" && !readStdin {
+		paths = append(paths, ".")
+
+		fmt.Println("No path specified. Defaulting to current directory.")
 	}
 
+	// Any errors from here on are not caused by the arguments, so the usage
+	// text would not help.
+	cmd.SilenceUsage = true
+
 	results := make(chan imageData)
 	errs := make(chan error)
 	scanDone := make(chan bool)
@@ -291,17 +737,49 @@ func imageSizes(compareOperator compareType, arguments []string) error {
 
 	scans := make(chan int, concurrency)
 
-	for i := 1; i < len(arguments); i++ {
+	for _, path := range paths {
 		wg.Add(1)
 
 		go func(path string) {
 			defer wg.Done()
 
-			err := walkPath(path, compare, scans, results)
+			err := scanPath(path, filter, scans, results)
+			if err != nil {
+				errs <- err
+			}
+		}(path)
+	}
+
+	var stdinMatched bool
+
+	if readStdin {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			var err error
+
+			stdinMatched, err = scanStdin(filter, results)
 			if err != nil {
 				errs <- err
 			}
-		}(arguments[i])
+		}()
+	}
+
+	// Listed paths often come from tools such as git diff --name-only, which
+	// include deleted files, so missing paths are skipped.
+	for _, path := range listed {
+		wg.Add(1)
+
+		go func(path string) {
+			defer wg.Done()
+
+			err := scanPath(path, filter, scans, results)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				errs <- err
+			}
+		}(path)
 	}
 
 	go func() {
@@ -322,11 +800,22 @@ Poll:
 
 	sortOutput(outputs)
 
-	if verbose {
-		for _, output := range outputs {
-			fmt.Printf("%v (%vx%v)\n", output.name, output.width, output.height)
+	// Images read from stdin are always described, as there is no file to
+	// inspect afterwards.
+	for _, output := range outputs {
+		if verbose || readStdin {
+			name := output.name
+			if output.target != "" {
+				name += " -> " + output.target
+			}
+
+			fmt.Printf("%v (%v)\n", name, describe(output))
+		} else {
+			fmt.Printf("%v\n", output.name)
 		}
+	}
 
+	if verbose {
 		if len(outputs) != 0 {
 			fmt.Println("")
 		}
@@ -335,10 +824,12 @@ Poll:
 			len(outputs),
 			time.Since(startTime),
 		)
-	} else {
-		for _, output := range outputs {
-			fmt.Printf("%v\n", output.name)
-		}
+	}
+
+	// When checking an image from stdin, the exit status reports whether it
+	// matched, whatever became of any other paths.
+	if readStdin && !stdinMatched {
+		return ErrNoMatch
 	}
 
 	return nil
@@ -9,11 +9,16 @@ import (
 )
 
 var widthUnderCmd = &cobra.Command{
-	Use:   "under <size in pixels> [directory1] ...[directoryN]",
+	Use:   "under <size in pixels> [path1] ...[pathN]",
 	Short: "Filter images by width",
 	Args:  cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		err := imageSizes(narrower, args)
+		filter, err := thresholdFilter(widthField, less, args[0])
+		if err != nil {
+			return err
+		}
+
+		err = imageSizes(cmd, filter, args[1:])
 		if err != nil {
 			return err
 		}